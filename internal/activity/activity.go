@@ -0,0 +1,187 @@
+package activity
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+// Kinds of activity recorded for members.
+const (
+	KindMessage    = "message"
+	KindVoice      = "voice"
+	KindAttendance = "attendance"
+	KindXpGain     = "xp gain"
+)
+
+// ActivityStorageKey is where the activity of every member is stored.
+const ActivityStorageKey = "activity/activity.json"
+
+// MemberActivity is the last time a member was seen doing each kind of activity.
+type MemberActivity struct {
+	// MemberKey is the key of the member, see memberlist.Member.Key.
+	MemberKey string `json:"member_key"`
+	// MemberName is the memberlist name of the member when their activity was last recorded.
+	MemberName string `json:"member_name"`
+	// LastSeen maps each kind of activity to when it last happened, in RFC3339.
+	LastSeen map[string]string `json:"last_seen"`
+}
+
+// LastActive returns the most recent activity of a member and its kind. The zero time is returned if there is none.
+func (m MemberActivity) LastActive() (time.Time, string) {
+	last := time.Time{}
+	lastKind := ""
+	for kind, date := range m.LastSeen {
+		seen, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			continue
+		}
+		if seen.After(last) {
+			last = seen
+			lastKind = kind
+		}
+	}
+
+	return last, lastKind
+}
+
+// Tracker records member activity in memory and saves it to the data store when flushed.
+type Tracker struct {
+	activity map[string]MemberActivity
+	// generation counts the changes recorded, and flushed is the generation last saved to the data store.
+	generation uint64
+	flushed    uint64
+	mu         sync.Mutex
+	// flushMu serialises flushes, so an older upload cannot overwrite a newer one.
+	flushMu sync.Mutex
+	// upload saves the activity to the data store; see storage.UploadJSON.
+	upload func(filename string, data interface{}) error
+}
+
+// LoadTracker creates a tracker from the activity in the data store.
+func LoadTracker() (*Tracker, error) {
+	activity := make(map[string]MemberActivity)
+	err := storage.DownloadJSON(ActivityStorageKey, &activity)
+	if err != nil && err != storage.ErrObjectNotFound {
+		return nil, err
+	}
+
+	return NewTracker(activity), nil
+}
+
+// NewTracker creates a tracker from existing activity, keyed by member key.
+func NewTracker(activity map[string]MemberActivity) *Tracker {
+	if activity == nil {
+		activity = make(map[string]MemberActivity)
+	}
+
+	return &Tracker{activity: activity, upload: storage.UploadJSON}
+}
+
+// Record records that a member did an activity at the given time. Older activity than already recorded is ignored.
+func (t *Tracker) Record(member memberlistentity.Member, kind string, date time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := member.Key()
+	memberActivity, ok := t.activity[key]
+	if !ok {
+		memberActivity = MemberActivity{MemberKey: key, LastSeen: make(map[string]string)}
+	}
+	memberActivity.MemberName = member.Name
+
+	if previous, err := time.Parse(time.RFC3339, memberActivity.LastSeen[kind]); err == nil && !date.After(previous) {
+		return
+	}
+	memberActivity.LastSeen[kind] = date.Format(time.RFC3339)
+	t.activity[key] = memberActivity
+	t.generation++
+}
+
+// Get returns the activity of a member.
+func (t *Tracker) Get(member memberlistentity.Member) MemberActivity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	memberActivity, ok := t.activity[member.Key()]
+	if !ok {
+		return MemberActivity{MemberKey: member.Key(), MemberName: member.Name, LastSeen: map[string]string{}}
+	}
+
+	return memberActivity
+}
+
+// Flush saves the recorded activity to the data store if it changed since the last flush. Activity is serialised under
+// the lock and uploaded without it, so recording is not blocked by the upload; activity recorded meanwhile is saved by
+// the next flush.
+func (t *Tracker) Flush() error {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.mu.Lock()
+	if t.generation == t.flushed {
+		t.mu.Unlock()
+		return nil
+	}
+	generation := t.generation
+	data, err := json.Marshal(t.activity)
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := t.upload(ActivityStorageKey, json.RawMessage(data)); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.flushed = generation
+	t.mu.Unlock()
+
+	return nil
+}
+
+// InactiveMember is a ranked member with no activity since a cutoff.
+type InactiveMember struct {
+	Member memberlistentity.Member
+	// LastActive is the zero time if the member was never seen.
+	LastActive time.Time
+	// Kind is the kind of the member's last activity.
+	Kind string
+}
+
+// FindInactive returns the members with a clan rank who have not been active since the cutoff, ordered by rank from
+// highest to lowest and then by how long they have been inactive.
+func (t *Tracker) FindInactive(members []memberlistentity.Member, cutoff time.Time) []InactiveMember {
+	inactive := []InactiveMember{}
+	ranks := make(map[string]memberlistentity.Rank)
+	for _, member := range members {
+		rank := memberlistentity.GetRankByName(member.Rank)
+		if rank == nil {
+			continue
+		}
+		ranks[member.Key()] = *rank
+
+		last, kind := t.Get(member).LastActive()
+		if last.After(cutoff) {
+			continue
+		}
+		member.Rank = rank.Name
+		inactive = append(inactive, InactiveMember{Member: member, LastActive: last, Kind: kind})
+	}
+
+	sort.SliceStable(inactive, func(i, j int) bool {
+		a, b := inactive[i], inactive[j]
+		aRank, bRank := ranks[a.Member.Key()], ranks[b.Member.Key()]
+		if aRank.RoleID != bRank.RoleID {
+			return aRank.Above(bRank)
+		}
+		return a.LastActive.Before(b.LastActive)
+	})
+
+	return inactive
+}
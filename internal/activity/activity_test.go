@@ -0,0 +1,75 @@
+package activity
+
+import (
+	"testing"
+	"time"
+
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func TestFindInactive(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	members := []memberlistentity.Member{
+		{Uuid: "1", Name: "active", Rank: "Member"},
+		{Uuid: "2", Name: "idle member", Rank: "Member"},
+		{Uuid: "3", Name: "never seen", Rank: "member"},
+		{Uuid: "4", Name: "idle officer", Rank: "Officer"},
+		{Uuid: "5", Name: "departed", Rank: memberlistentity.DepartedRankName},
+	}
+
+	tracker := NewTracker(nil)
+	tracker.Record(members[0], KindMessage, now.AddDate(0, 0, -40))
+	tracker.Record(members[0], KindVoice, now.AddDate(0, 0, -2))
+	tracker.Record(members[1], KindAttendance, now.AddDate(0, 0, -45))
+	tracker.Record(members[3], KindXpGain, now.AddDate(0, 0, -31))
+	// Older activity must not replace newer activity.
+	tracker.Record(members[0], KindVoice, now.AddDate(0, 0, -60))
+
+	inactive := tracker.FindInactive(members, now.AddDate(0, 0, -30))
+	if len(inactive) != 3 {
+		t.Fatalf("Expected 3 inactive members, got %+v", inactive)
+	}
+
+	expected := []string{"idle officer", "never seen", "idle member"}
+	for i, name := range expected {
+		if inactive[i].Member.Name != name {
+			t.Errorf("Expected %s at position %d, got %s", name, i, inactive[i].Member.Name)
+		}
+	}
+	if inactive[0].Kind != KindXpGain {
+		t.Errorf("Expected idle officer's last activity to be an xp gain, got %s", inactive[0].Kind)
+	}
+	if !inactive[1].LastActive.IsZero() {
+		t.Errorf("Expected never seen to have no activity, got %s", inactive[1].LastActive)
+	}
+}
+
+func TestFlushKeepsActivityRecordedDuringUpload(t *testing.T) {
+	t.Parallel()
+
+	member := memberlistentity.Member{Uuid: "1", Name: "joey", Rank: "Member"}
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker(nil)
+	tracker.Record(member, KindMessage, now)
+
+	uploads := 0
+	tracker.upload = func(filename string, data interface{}) error {
+		uploads++
+		if uploads == 1 {
+			// Recording while an upload is in progress must neither block nor be lost.
+			tracker.Record(member, KindVoice, now)
+		}
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := tracker.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if uploads != 2 {
+		t.Errorf("Expected activity recorded during the first upload to be saved by the second flush only, got %d uploads", uploads)
+	}
+}
@@ -0,0 +1,154 @@
+package applications
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+// Statuses an application can be in.
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+	StatusRejected = "rejected"
+)
+
+// ApplicantRankName is the rank given to accepted applicants.
+const ApplicantRankName = "Applicant"
+
+// CombatLevelRule is the range of combat levels accepted for an account.
+type CombatLevelRule struct {
+	Account string
+	Minimum int64
+	Maximum int64
+}
+
+// COMBAT_LEVEL_RULES are the clan's combat level requirements per account.
+var COMBAT_LEVEL_RULES []CombatLevelRule = []CombatLevelRule{
+	{Account: memberlistentity.AccountLPC, Minimum: 40, Maximum: 90},
+	{Account: memberlistentity.AccountXLPC, Minimum: 3, Maximum: 60},
+}
+
+var ErrApplicationNotPending error = errors.New("application has already been reviewed")
+
+type Application struct {
+	// Uuid is the uuid of the application.
+	Uuid string `json:"uuid"`
+	// DiscordID is the Discord ID of the applicant.
+	DiscordID string `json:"discord_id"`
+	// DiscordName is the Discord username of the applicant.
+	DiscordName string `json:"discord_name"`
+	// Name is the name the applicant wants on the memberlist.
+	Name string `json:"name"`
+	// TeamSpeakID is the TeamSpeak ID of the applicant.
+	TeamSpeakID string `json:"teamspeak_id"`
+	// Accounts are the applicant's RuneScape accounts.
+	Accounts memberlistentity.RuneScapeAccounts `json:"runescape_accounts"`
+	// About is what the applicant wrote about themselves.
+	About string `json:"about"`
+	// Problems are the issues found when verifying the application.
+	Problems []string `json:"problems"`
+	// Status is the review status of the application.
+	Status string `json:"status"`
+	// ReviewedBy is the Discord username of the officer who reviewed the application.
+	ReviewedBy string `json:"reviewed_by"`
+	// Date is the date the application was submitted.
+	Date string `json:"date"`
+}
+
+// NewApplication creates a new pending application.
+func NewApplication(discordID string, discordName string, name string, accounts memberlistentity.RuneScapeAccounts, teamSpeakID string, about string) *Application {
+	return &Application{
+		Uuid:        uuid.New().String(),
+		DiscordID:   discordID,
+		DiscordName: discordName,
+		Name:        name,
+		TeamSpeakID: teamSpeakID,
+		Accounts:    accounts,
+		About:       about,
+		Problems:    []string{},
+		Status:      StatusPending,
+		Date:        time.Now().Format(time.RFC3339),
+	}
+}
+
+// Verify looks the applicant's accounts up on the hiscores and records every problem found against COMBAT_LEVEL_RULES.
+func (a *Application) Verify(lookup hiscores.Lookup) {
+	member := a.ToMember()
+	a.Problems = []string{}
+	provided := 0
+	for _, rule := range COMBAT_LEVEL_RULES {
+		rsn := member.GetAccount(rule.Account)
+		if len(rsn) == 0 {
+			continue
+		}
+		provided++
+
+		player, err := lookup(rsn)
+		if errors.Is(err, hiscores.ErrPlayerNotFound) {
+			a.Problems = append(a.Problems, fmt.Sprintf("%s RSN %s is not on the hiscores", rule.Account, rsn))
+			continue
+		}
+		if err != nil {
+			a.Problems = append(a.Problems, fmt.Sprintf("%s RSN %s could not be checked: %v", rule.Account, rsn, err))
+			continue
+		}
+
+		level := player.CombatLevel()
+		if level < rule.Minimum || level > rule.Maximum {
+			a.Problems = append(a.Problems, fmt.Sprintf("%s %s is combat %d, outside %d-%d", rule.Account, rsn, level, rule.Minimum, rule.Maximum))
+		}
+	}
+
+	if provided == 0 {
+		a.Problems = append(a.Problems, "no RSNs were provided")
+	}
+}
+
+// ToMember converts the application into a memberlist entry with the Applicant rank.
+func (a *Application) ToMember() memberlistentity.Member {
+	return memberlistentity.Member{
+		Uuid:        uuid.New().String(),
+		Name:        a.Name,
+		Rank:        ApplicantRankName,
+		DiscordID:   a.DiscordID,
+		TeamSpeakID: a.TeamSpeakID,
+		Accounts:    a.Accounts,
+	}
+}
+
+// Review marks a pending application as accepted or rejected.
+func (a *Application) Review(status string, reviewedBy string) error {
+	if a.Status != StatusPending {
+		return ErrApplicationNotPending
+	}
+
+	a.Status = status
+	a.ReviewedBy = reviewedBy
+	return nil
+}
+
+func storageKey(uuid string) string {
+	return fmt.Sprintf("applications/%s.json", uuid)
+}
+
+// Save saves the application to the data store.
+func (a *Application) Save() error {
+	return storage.UploadJSON(storageKey(a.Uuid), a)
+}
+
+// GetApplicationByUUID returns an application by uuid.
+func GetApplicationByUUID(uuid string) (*Application, error) {
+	application := &Application{}
+	err := storage.DownloadJSON(storageKey(uuid), application)
+	if err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
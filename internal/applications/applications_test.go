@@ -0,0 +1,48 @@
+package applications
+
+import (
+	"testing"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(rsn string) (*hiscores.Player, error) {
+		if rsn == "renamed" {
+			return nil, hiscores.ErrPlayerNotFound
+		}
+
+		player := &hiscores.Player{RuneScapeName: rsn, Skills: map[string]hiscores.Skill{}}
+		for _, skill := range hiscores.SKILLS {
+			player.Skills[skill] = hiscores.Skill{Level: 99}
+		}
+		return player, nil
+	}
+
+	application := NewApplication("1", "joey#0001", "joey", memberlistentity.RuneScapeAccounts{{Tag: memberlistentity.AccountLPC, RuneScapeName: "maxed"}, {Tag: memberlistentity.AccountXLPC, RuneScapeName: "renamed"}}, "", "")
+	application.Verify(lookup)
+	if len(application.Problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", application.Problems)
+	}
+
+	empty := NewApplication("2", "corgi#0001", "corgi", memberlistentity.RuneScapeAccounts{}, "", "")
+	empty.Verify(lookup)
+	if len(empty.Problems) != 1 {
+		t.Errorf("Expected a missing RSN problem, got %v", empty.Problems)
+	}
+}
+
+func TestReview(t *testing.T) {
+	t.Parallel()
+
+	application := NewApplication("1", "joey#0001", "joey", memberlistentity.RuneScapeAccounts{}, "", "")
+	if err := application.Review(StatusAccepted, "officer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := application.Review(StatusRejected, "officer"); err != ErrApplicationNotPending {
+		t.Errorf("Expected ErrApplicationNotPending, got %v", err)
+	}
+}
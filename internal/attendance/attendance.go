@@ -0,0 +1,101 @@
+package attendance
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+// Attendee is a TeamSpeak client present when an attendance snapshot was taken.
+type Attendee struct {
+	// Nickname is the TeamSpeak nickname of the attendee.
+	Nickname string `json:"nickname"`
+	// DatabaseID is the TeamSpeak database ID of the attendee.
+	DatabaseID int `json:"database_id"`
+	// MemberKey is the key of the memberlist member the attendee was matched to, if any.
+	MemberKey string `json:"member_key"`
+}
+
+type AttendanceSnapshot struct {
+	// Uuid is the uuid of the snapshot.
+	Uuid string `json:"uuid"`
+	// Name is the name of the snapshot, usually the event it was taken for.
+	Name string `json:"name"`
+	// Date is the date the snapshot was taken.
+	Date string `json:"date"`
+	// Attendees is a list of clients present in the event channels.
+	Attendees []Attendee `json:"attendees"`
+}
+
+// MatchAttendee finds the member a TeamSpeak client belongs to, comparing the member's TeamSpeak ID with the
+// client's database ID and nickname.
+func MatchAttendee(members []memberlistentity.Member, nickname string, databaseID int) *memberlistentity.Member {
+	for _, v := range members {
+		if len(v.TeamSpeakID) == 0 {
+			continue
+		}
+		if v.TeamSpeakID == strconv.Itoa(databaseID) || strings.EqualFold(v.TeamSpeakID, nickname) {
+			return &v
+		}
+	}
+
+	return nil
+}
+
+// NewAttendanceSnapshot creates a new attendance snapshot and saves it to the data store.
+func NewAttendanceSnapshot(name string, attendees []Attendee) (*AttendanceSnapshot, error) {
+	snapshot := &AttendanceSnapshot{
+		Uuid:      uuid.New().String(),
+		Name:      name,
+		Date:      time.Now().Format(time.RFC3339),
+		Attendees: attendees,
+	}
+
+	err := storage.UploadJSON(fmt.Sprintf("attendance/%s.json", snapshot.Uuid), snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// GetAttendanceSnapshots returns every stored attendance snapshot.
+func GetAttendanceSnapshots() ([]AttendanceSnapshot, error) {
+	files, err := storage.ListObjects("attendance/")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []AttendanceSnapshot{}
+	for _, file := range files {
+		snapshot := AttendanceSnapshot{}
+		if err := storage.DownloadJSON(file, &snapshot); err != nil {
+			log.Printf("Failed to download attendance snapshot %s: %v", file, err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// GetMemberAttendance returns the snapshots a member attended.
+func GetMemberAttendance(snapshots []AttendanceSnapshot, member memberlistentity.Member) []AttendanceSnapshot {
+	attended := []AttendanceSnapshot{}
+	for _, snapshot := range snapshots {
+		for _, attendee := range snapshot.Attendees {
+			if attendee.MemberKey == member.Key() {
+				attended = append(attended, snapshot)
+				break
+			}
+		}
+	}
+
+	return attended
+}
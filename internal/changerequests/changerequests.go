@@ -0,0 +1,122 @@
+package changerequests
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+// Statuses a change request can be in.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusDenied   = "denied"
+)
+
+// FieldTeamSpeak is the field of a change request updating the member's TeamSpeak ID. Any other field is an account tag.
+const FieldTeamSpeak = "teamspeak"
+
+var ErrChangeRequestNotPending error = errors.New("change request has already been reviewed")
+var ErrChangeRequestOutdated error = errors.New("member has changed since the change request was made")
+
+// ChangeRequest is a member's request to change a field of their own memberlist entry.
+type ChangeRequest struct {
+	// Uuid is the uuid of the change request.
+	Uuid string `json:"uuid"`
+	// MemberKey is the key of the member, see memberlist.Member.Key.
+	MemberKey string `json:"member_key"`
+	// MemberName is the memberlist name of the member.
+	MemberName string `json:"member_name"`
+	// DiscordID is the Discord ID of the member who made the request.
+	DiscordID string `json:"discord_id"`
+	// Field is FieldTeamSpeak or the tag of the account to change.
+	Field string `json:"field"`
+	// OldValue is the value of the field when the request was made.
+	OldValue string `json:"old_value"`
+	// Value is the requested value of the field.
+	Value string `json:"value"`
+	// Notes are remarks for the reviewing officer, such as RSNs that could not be checked.
+	Notes []string `json:"notes"`
+	// Status is the review status of the change request.
+	Status string `json:"status"`
+	// ReviewedBy is the Discord username of the officer who reviewed the change request.
+	ReviewedBy string `json:"reviewed_by"`
+	// Date is the date the change request was made.
+	Date string `json:"date"`
+}
+
+// GetField returns the current value of a change request field on a member.
+func GetField(member memberlistentity.Member, field string) string {
+	if field == FieldTeamSpeak {
+		return member.TeamSpeakID
+	}
+
+	return member.GetAccount(field)
+}
+
+// NewChangeRequest creates a new pending change request for a member.
+func NewChangeRequest(member memberlistentity.Member, field string, value string) *ChangeRequest {
+	return &ChangeRequest{
+		Uuid:       uuid.New().String(),
+		MemberKey:  member.Key(),
+		MemberName: member.Name,
+		DiscordID:  member.DiscordID,
+		Field:      field,
+		OldValue:   GetField(member, field),
+		Value:      value,
+		Notes:      []string{},
+		Status:     StatusPending,
+		Date:       time.Now().Format(time.RFC3339),
+	}
+}
+
+// Apply returns a copy of the member with the requested change made. ErrChangeRequestOutdated is returned if the field
+// no longer holds the value it had when the change was requested, e.g. because the change was already applied.
+func (c *ChangeRequest) Apply(member memberlistentity.Member) (memberlistentity.Member, error) {
+	if GetField(member, c.Field) != c.OldValue {
+		return member, ErrChangeRequestOutdated
+	}
+
+	if c.Field == FieldTeamSpeak {
+		member.TeamSpeakID = c.Value
+		return member, nil
+	}
+
+	err := member.SetAccount(c.Field, c.Value)
+	return member, err
+}
+
+// Review marks a pending change request as approved or denied.
+func (c *ChangeRequest) Review(status string, reviewedBy string) error {
+	if c.Status != StatusPending {
+		return ErrChangeRequestNotPending
+	}
+
+	c.Status = status
+	c.ReviewedBy = reviewedBy
+	return nil
+}
+
+func storageKey(uuid string) string {
+	return fmt.Sprintf("changerequests/%s.json", uuid)
+}
+
+// Save saves the change request to the data store.
+func (c *ChangeRequest) Save() error {
+	return storage.UploadJSON(storageKey(c.Uuid), c)
+}
+
+// GetChangeRequestByUUID returns a change request by uuid.
+func GetChangeRequestByUUID(uuid string) (*ChangeRequest, error) {
+	request := &ChangeRequest{}
+	err := storage.DownloadJSON(storageKey(uuid), request)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
@@ -0,0 +1,42 @@
+package changerequests
+
+import (
+	"testing"
+
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	member := memberlistentity.Member{Uuid: "1", Name: "joey", TeamSpeakID: "old="}
+	member.SetAccount(memberlistentity.AccountLPC, "bender life")
+
+	request := NewChangeRequest(member, memberlistentity.AccountLPC, "bender lyfe")
+	if request.OldValue != "bender life" || request.MemberKey != "1" {
+		t.Errorf("Expected the old value and member key to be recorded, got %+v", request)
+	}
+	updated, err := request.Apply(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetAccount(memberlistentity.AccountLPC) != "bender lyfe" || member.GetAccount(memberlistentity.AccountLPC) != "bender life" {
+		t.Errorf("Expected only the copy to change, got %+v and %+v", updated.Accounts, member.Accounts)
+	}
+
+	if _, err := request.Apply(updated); err != ErrChangeRequestOutdated {
+		t.Errorf("Expected ErrChangeRequestOutdated once the change is applied, got %v", err)
+	}
+
+	updated, _ = NewChangeRequest(member, FieldTeamSpeak, "new=").Apply(member)
+	if updated.TeamSpeakID != "new=" {
+		t.Errorf("Expected the TeamSpeak ID to change, got %s", updated.TeamSpeakID)
+	}
+
+	if err := request.Review(StatusApproved, "officer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := request.Review(StatusDenied, "officer"); err != ErrChangeRequestNotPending {
+		t.Errorf("Expected ErrChangeRequestNotPending, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+// Package chart renders simple line charts as PNG images.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	WIDTH  = 900
+	HEIGHT = 480
+	// MARGIN_LEFT leaves room for the value labels.
+	MARGIN_LEFT = 70
+	// MARGIN_RIGHT leaves room for the legend.
+	MARGIN_RIGHT  = 170
+	MARGIN_TOP    = 40
+	MARGIN_BOTTOM = 40
+	// VALUE_TICKS is how many horizontal grid lines divide the values.
+	VALUE_TICKS = 5
+	// MAXIMUM_LABEL_LENGTH is how many characters of a series name the legend shows.
+	MAXIMUM_LABEL_LENGTH = 18
+)
+
+var ErrNoPoints error = errors.New("nothing to chart")
+
+// PALETTE is the colours series are drawn in, in order.
+var PALETTE []color.RGBA = []color.RGBA{
+	{0x25, 0x63, 0xeb, 0xff},
+	{0xdc, 0x26, 0x26, 0xff},
+	{0x16, 0xa3, 0x4a, 0xff},
+	{0xd9, 0x77, 0x06, 0xff},
+	{0x93, 0x33, 0xea, 0xff},
+	{0x08, 0x91, 0xb2, 0xff},
+	{0xdb, 0x27, 0x77, 0xff},
+	{0x65, 0xa3, 0x0d, 0xff},
+	{0x57, 0x53, 0x4e, 0xff},
+	{0x1e, 0x3a, 0x8a, 0xff},
+}
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	grid       = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	axis       = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	text       = color.RGBA{0x11, 0x18, 0x27, 0xff}
+)
+
+// Point is a value at a point in time.
+type Point struct {
+	Time  time.Time
+	Value int64
+}
+
+// Series is a named line on a chart.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// bounds returns the earliest and latest time and the largest value of the series.
+func bounds(series []Series) (time.Time, time.Time, int64, bool) {
+	var first, last time.Time
+	var max int64
+	found := false
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !found || p.Time.Before(first) {
+				first = p.Time
+			}
+			if !found || p.Time.After(last) {
+				last = p.Time
+			}
+			if p.Value > max {
+				max = p.Value
+			}
+			found = true
+		}
+	}
+
+	return first, last, max, found
+}
+
+// FormatValue abbreviates a value for an axis label, e.g. 12.5k or 1.2M.
+func FormatValue(value int64) string {
+	switch {
+	case value >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(value)/1000000)
+	case value >= 1000:
+		return fmt.Sprintf("%.1fk", float64(value)/1000)
+	default:
+		return fmt.Sprintf("%d", value)
+	}
+}
+
+// drawText draws a string with its baseline starting at x, y.
+func drawText(img draw.Image, x int, y int, s string, c color.Color) {
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(s)
+}
+
+// textWidth returns the width of a string in pixels.
+func textWidth(s string) int {
+	return font.MeasureString(basicfont.Face7x13, s).Round()
+}
+
+// drawLine draws a line two pixels thick between two points.
+func drawLine(img draw.Image, x0 int, y0 int, x1 int, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	err := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		img.Set(x0+1, y0, c)
+		img.Set(x0, y0+1, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// fillRect fills a rectangle.
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+// RenderLineChart renders the series as a PNG line chart of value over time, with a legend naming each series.
+// ErrNoPoints is returned if no series has any points.
+func RenderLineChart(title string, series []Series) ([]byte, error) {
+	first, last, max, ok := bounds(series)
+	if !ok {
+		return nil, ErrNoPoints
+	}
+	if max <= 0 {
+		max = 1
+	}
+	span := last.Sub(first)
+	if span <= 0 {
+		span = time.Second
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	fillRect(img, img.Bounds(), background)
+	plot := image.Rect(MARGIN_LEFT, MARGIN_TOP, WIDTH-MARGIN_RIGHT, HEIGHT-MARGIN_BOTTOM)
+
+	x := func(t time.Time) int {
+		return plot.Min.X + int(float64(plot.Dx())*float64(t.Sub(first))/float64(span))
+	}
+	y := func(v int64) int {
+		return plot.Max.Y - int(float64(plot.Dy())*float64(v)/float64(max))
+	}
+
+	drawText(img, MARGIN_LEFT, MARGIN_TOP-15, title, text)
+
+	for i := 0; i <= VALUE_TICKS; i++ {
+		value := max * int64(i) / VALUE_TICKS
+		gy := y(value)
+		fillRect(img, image.Rect(plot.Min.X, gy, plot.Max.X, gy+1), grid)
+		label := FormatValue(value)
+		drawText(img, plot.Min.X-textWidth(label)-6, gy+4, label, axis)
+	}
+
+	fillRect(img, image.Rect(plot.Min.X, plot.Min.Y, plot.Min.X+1, plot.Max.Y+1), axis)
+	fillRect(img, image.Rect(plot.Min.X, plot.Max.Y, plot.Max.X+1, plot.Max.Y+1), axis)
+
+	layout := "Jan 2 15:04"
+	startLabel, endLabel := first.UTC().Format(layout), last.UTC().Format(layout)+" UTC"
+	drawText(img, plot.Min.X, plot.Max.Y+18, startLabel, axis)
+	drawText(img, plot.Max.X-textWidth(endLabel), plot.Max.Y+18, endLabel, axis)
+
+	for i, s := range series {
+		c := PALETTE[i%len(PALETTE)]
+		for j := 1; j < len(s.Points); j++ {
+			drawLine(img, x(s.Points[j-1].Time), y(s.Points[j-1].Value), x(s.Points[j].Time), y(s.Points[j].Value), c)
+		}
+		if len(s.Points) == 1 {
+			fillRect(img, image.Rect(x(s.Points[0].Time)-2, y(s.Points[0].Value)-2, x(s.Points[0].Time)+3, y(s.Points[0].Value)+3), c)
+		}
+
+		name := s.Name
+		if len(name) > MAXIMUM_LABEL_LENGTH {
+			name = name[:MAXIMUM_LABEL_LENGTH]
+		}
+		ly := MARGIN_TOP + i*18
+		fillRect(img, image.Rect(plot.Max.X+15, ly, plot.Max.X+25, ly+10), c)
+		drawText(img, plot.Max.X+31, ly+10, name, text)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := png.Encode(buffer, img); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,45 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestRenderLineChart(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC)
+	series := []Series{
+		{Name: "Corgi", Points: []Point{{start, 0}, {start.Add(time.Hour), 50000}, {start.Add(2 * time.Hour), 1200000}}},
+		{Name: "A very long participant name", Points: []Point{{start, 0}, {start.Add(2 * time.Hour), 300}}},
+		{Name: "Single", Points: []Point{{start.Add(time.Hour), 10}}},
+	}
+
+	data, err := RenderLineChart("Combat Week", series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a valid PNG, got %v", err)
+	}
+	if img.Bounds().Dx() != WIDTH || img.Bounds().Dy() != HEIGHT {
+		t.Errorf("Unexpected chart size %v", img.Bounds())
+	}
+
+	if _, err := RenderLineChart("Empty", []Series{{Name: "Nobody"}}); err != ErrNoPoints {
+		t.Errorf("Expected empty chart to be refused, got %v", err)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	t.Parallel()
+
+	for value, expected := range map[int64]string{0: "0", 999: "999", 12500: "12.5k", 1250000: "1.2M"} {
+		if formatted := FormatValue(value); formatted != expected {
+			t.Errorf("Expected %d to format as %s, got %s", value, expected, formatted)
+		}
+	}
+}
@@ -3,4 +3,10 @@ package discord
 const (
 	GuildID                     = "692873850530168843"
 	AdminNotificationsChannelID = "1082687782331351090"
+	// ApplicationReviewChannelID is where officers review clan applications.
+	ApplicationReviewChannelID = AdminNotificationsChannelID
+	// ChangeRequestReviewChannelID is where officers review members' changes to their own memberlist entries.
+	ChangeRequestReviewChannelID = AdminNotificationsChannelID
+	// XpTrackerResultsChannelID is where scheduled xp tracker events announce their start and post their results.
+	XpTrackerResultsChannelID = AdminNotificationsChannelID
 )
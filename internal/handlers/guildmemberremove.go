@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	"github.com/joeydotdev/corgi-discord-bot/internal/plugins"
+)
+
+// GuildMemberRemove processes members leaving or being kicked from the guild.
+// https://discord.com/developers/docs/topics/gateway-events#guild-member-remove
+func (h *Handler) GuildMemberRemove(session *discordgo.Session, guildMemberRemove *discordgo.GuildMemberRemove) {
+	if guildMemberRemove.Member == nil || guildMemberRemove.User == nil || guildMemberRemove.GuildID != discord.GuildID {
+		return
+	}
+
+	if err := plugins.HandleDeparture(session, guildMemberRemove.User); err != nil {
+		log.Printf("Failed to handle departure of %s: %v", guildMemberRemove.User.ID, err)
+	}
+}
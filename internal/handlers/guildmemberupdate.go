@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	"github.com/joeydotdev/corgi-discord-bot/internal/plugins"
+)
+
+// GuildMemberAdd processes members joining the guild.
+// https://discord.com/developers/docs/topics/gateway-events#guild-member-add
+func (h *Handler) GuildMemberAdd(session *discordgo.Session, guildMemberAdd *discordgo.GuildMemberAdd) {
+	if guildMemberAdd.Member == nil || guildMemberAdd.GuildID != discord.GuildID {
+		return
+	}
+
+	plugins.TrackClanRank(guildMemberAdd.Member)
+}
+
+// GuildMemberUpdate processes changes to guild members, such as their roles.
+// https://discord.com/developers/docs/topics/gateway-events#guild-member-update
+func (h *Handler) GuildMemberUpdate(session *discordgo.Session, guildMemberUpdate *discordgo.GuildMemberUpdate) {
+	if guildMemberUpdate.Member == nil || guildMemberUpdate.GuildID != discord.GuildID {
+		return
+	}
+
+	plugins.TrackClanRank(guildMemberUpdate.Member)
+}
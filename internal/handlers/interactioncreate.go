@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/plugins"
+)
+
+var interactionCreatePluginsMap map[string]plugins.InteractionPlugin
+
+// init initializes the interactionCreatePluginsMap with all plugins that implement the InteractionPlugin interface.
+func init() {
+	interactionCreatePluginsMap = make(map[string]plugins.InteractionPlugin)
+	interactionCreatePluginsMap[plugins.RSNChangePluginName] = plugins.NewRSNChangePlugin()
+	interactionCreatePluginsMap[plugins.ManageMemberlistPluginName] = plugins.NewManageMemberlistPlugin()
+	interactionCreatePluginsMap[plugins.ApplicationPluginName] = plugins.NewApplicationPlugin()
+	interactionCreatePluginsMap[plugins.DeparturePluginName] = plugins.NewDeparturePlugin()
+	interactionCreatePluginsMap[plugins.MeCommandPluginName] = plugins.NewMeCommandPlugin()
+	interactionCreatePluginsMap[plugins.ManageXpTrackerPluginName] = plugins.NewManageXpTrackerPlugin()
+}
+
+// respondWithError tells the user who triggered an interaction that it failed. Only they can see the response.
+func respondWithError(session *discordgo.Session, interactionCreate *discordgo.InteractionCreate, err error) {
+	respondErr := session.InteractionRespond(interactionCreate.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: err.Error(),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if respondErr == nil {
+		return
+	}
+
+	// The plugin may have already responded to the interaction, in which case a follow-up is required.
+	_, respondErr = session.FollowupMessageCreate(interactionCreate.Interaction, false, &discordgo.WebhookParams{
+		Content: err.Error(),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if respondErr != nil {
+		fmt.Println("Failed to respond to interaction: ", respondErr)
+	}
+}
+
+// InteractionCreate processes interaction create events emitted from Discord API
+// https://discord.com/developers/docs/topics/gateway-events#interaction-create
+func (h *Handler) InteractionCreate(session *discordgo.Session, interactionCreate *discordgo.InteractionCreate) {
+	for _, plugin := range interactionCreatePluginsMap {
+		if !plugin.Enabled() {
+			// Skip disabled plugins
+			continue
+		}
+
+		if plugin.ValidateInteraction(session, interactionCreate) {
+			err := plugin.ExecuteInteraction(session, interactionCreate)
+			if err != nil {
+				respondWithError(session, interactionCreate, err)
+			}
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/activity"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
 	"github.com/joeydotdev/corgi-discord-bot/internal/plugins"
 )
 
@@ -19,6 +21,12 @@ func init() {
 	messageCreatePluginsMap[plugins.MissingMembersPluginName] = plugins.NewMissingMembersPlugin()
 	messageCreatePluginsMap[plugins.MassPMCommandPluginName] = plugins.NewMassPMCommandPlugin()
 	messageCreatePluginsMap[plugins.MissingSignupsPluginName] = plugins.NewMissingSignupsPlugin()
+	messageCreatePluginsMap[plugins.RankChangeCommandPluginName] = plugins.NewRankChangeCommandPlugin()
+	messageCreatePluginsMap[plugins.WhoisCommandPluginName] = plugins.NewWhoisCommandPlugin()
+	messageCreatePluginsMap[plugins.ProfileCommandPluginName] = plugins.NewProfileCommandPlugin()
+	messageCreatePluginsMap[plugins.ApplicationPluginName] = plugins.NewApplicationPlugin()
+	messageCreatePluginsMap[plugins.InactiveCommandPluginName] = plugins.NewInactiveCommandPlugin()
+	messageCreatePluginsMap[plugins.MeCommandPluginName] = plugins.NewMeCommandPlugin()
 
 	// TODO: This is a temporary hack to get attendance working. We need to figure out a better way to do this.
 	if plugin := plugins.NewAttendanceCommandPlugin(); plugin != nil {
@@ -34,6 +42,9 @@ func (h *Handler) MessageCreate(session *discordgo.Session, messageCreate *disco
 		// Ignore messages sent by the bot
 		return
 	}
+	if messageCreate.GuildID == discord.GuildID {
+		plugins.RecordDiscordActivity(messageCreate.Author.ID, activity.KindMessage)
+	}
 
 	for _, plugin := range messageCreatePluginsMap {
 		fmt.Println("Processing plugin: ", plugin.Name())
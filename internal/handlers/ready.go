@@ -4,10 +4,21 @@ import (
 	"log"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/plugins"
 )
 
 // Ready processes ready events emitted from Discord API
 // https://discordapp.com/developers/docs/topics/gateway#ready
 func (h *Handler) Ready(session *discordgo.Session, _ready *discordgo.Ready) {
 	log.Println("[ReadyHandler] ready")
+	plugins.StartRSNChangeDetectionJob(session)
+	plugins.StartActivityFlushJob()
+	if err := plugins.CacheClanRanks(session); err != nil {
+		log.Printf("Failed to cache clan ranks: %v", err)
+	}
+	if err := plugins.RestoreXpTrackerEvents(); err != nil {
+		log.Printf("Failed to restore active xp tracker events: %v", err)
+	}
+	plugins.StartXpScheduleJob(session)
+	plugins.StartXpSnapshotJob()
 }
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/activity"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	"github.com/joeydotdev/corgi-discord-bot/internal/plugins"
+)
+
+// VoiceStateUpdate processes members joining, leaving or moving between voice channels.
+// https://discord.com/developers/docs/topics/gateway-events#voice-state-update
+func (h *Handler) VoiceStateUpdate(session *discordgo.Session, voiceStateUpdate *discordgo.VoiceStateUpdate) {
+	if voiceStateUpdate.VoiceState == nil || voiceStateUpdate.GuildID != discord.GuildID || len(voiceStateUpdate.ChannelID) == 0 {
+		// Ignore other guilds and members leaving voice
+		return
+	}
+
+	if voiceStateUpdate.BeforeUpdate != nil && voiceStateUpdate.BeforeUpdate.ChannelID == voiceStateUpdate.ChannelID {
+		// Ignore mutes, deafens and other changes within the same channel
+		return
+	}
+
+	plugins.RecordDiscordActivity(voiceStateUpdate.UserID, activity.KindVoice)
+}
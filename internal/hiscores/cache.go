@@ -0,0 +1,90 @@
+package hiscores
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a cached lookup and when it expires.
+type cacheEntry struct {
+	player  *Player
+	err     error
+	expires time.Time
+}
+
+// CachingClient is a Client remembering lookups for a while. Players that are not on the hiscores are cached too, but
+// lookups failing because the hiscores are unavailable are not. Expired lookups are evicted as new ones are cached.
+type CachingClient struct {
+	client Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// pruned is when expired entries were last evicted.
+	pruned time.Time
+}
+
+// NewCachingClient creates a new CachingClient remembering the lookups of client for ttl.
+func NewCachingClient(client Client, ttl time.Duration) *CachingClient {
+	return &CachingClient{
+		client:  client,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// cacheKey returns the key of a lookup. RSNs are not case sensitive.
+func cacheKey(rsn string, accountType AccountType) string {
+	return string(accountType) + "/" + strings.ToLower(strings.TrimSpace(rsn))
+}
+
+// GetPlayer looks a player up on the hiscores of the given account type, unless the lookup is cached.
+func (c *CachingClient) GetPlayer(rsn string, accountType AccountType) (*Player, error) {
+	key := cacheKey(rsn, accountType)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.player, entry.err
+	}
+
+	player, err := c.client.GetPlayer(rsn, accountType)
+	if err != nil && !errors.Is(err, ErrPlayerNotFound) {
+		return nil, err
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	c.prune(now)
+	c.entries[key] = cacheEntry{player: player, err: err, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return player, err
+}
+
+// prune evicts expired entries, at most once per ttl. The caller must hold mu.
+func (c *CachingClient) prune(now time.Time) {
+	if now.Sub(c.pruned) < c.ttl {
+		return
+	}
+
+	c.pruned = now
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Forget removes a player from the cache, e.g. after they were renamed.
+func (c *CachingClient) Forget(rsn string, accountType AccountType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, cacheKey(rsn, accountType))
+}
@@ -0,0 +1,108 @@
+package hiscores
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// HiscoresBaseURL is the URL of the Old School RuneScape website hosting the hiscores.
+	HiscoresBaseURL = "https://secure.runescape.com"
+	// RequestTimeout is how long a single hiscores request may take.
+	RequestTimeout = 15 * time.Second
+)
+
+// AccountType is the game mode of an account, each of which has its own hiscores.
+type AccountType string
+
+const (
+	AccountTypeNormal          AccountType = "normal"
+	AccountTypeIronman         AccountType = "ironman"
+	AccountTypeHardcoreIronman AccountType = "hardcore_ironman"
+	AccountTypeUltimateIronman AccountType = "ultimate_ironman"
+)
+
+// hiscoresPaths maps account types to the path of their hiscores lite endpoint.
+var hiscoresPaths map[AccountType]string = map[AccountType]string{
+	AccountTypeNormal:          "/m=hiscore_oldschool/index_lite.json",
+	AccountTypeIronman:         "/m=hiscore_oldschool_ironman/index_lite.json",
+	AccountTypeHardcoreIronman: "/m=hiscore_oldschool_hardcore_ironman/index_lite.json",
+	AccountTypeUltimateIronman: "/m=hiscore_oldschool_ultimate/index_lite.json",
+}
+
+var ErrUnknownAccountType error = errors.New("unknown account type")
+
+// ParseAccountType parses an account type, ignoring case.
+func ParseAccountType(value string) (AccountType, error) {
+	accountType := AccountType(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := hiscoresPaths[accountType]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownAccountType, value)
+	}
+
+	return accountType, nil
+}
+
+// Path returns the path of the account type's hiscores lite endpoint.
+func (a AccountType) Path() string {
+	return hiscoresPaths[a]
+}
+
+// Client looks players up on the hiscores.
+type Client interface {
+	// GetPlayer looks a player up on the hiscores of the given account type.
+	// ErrPlayerNotFound is returned if the player does not exist, and an error wrapping ErrHiscoresUnavailable if the hiscores could not be reached.
+	GetPlayer(rsn string, accountType AccountType) (*Player, error)
+}
+
+// HTTPClient is a Client requesting the hiscores lite endpoints.
+type HTTPClient struct {
+	// BaseURL is the URL the hiscores lite paths are appended to.
+	BaseURL string
+	// HTTP is the client requests are made with.
+	HTTP *http.Client
+}
+
+// DefaultClient looks players up on the live hiscores.
+var DefaultClient Client = NewHTTPClient(HiscoresBaseURL)
+
+// NewHTTPClient creates a new HTTPClient requesting the hiscores hosted at baseURL.
+func NewHTTPClient(baseURL string) *HTTPClient {
+	return &HTTPClient{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		HTTP:    &http.Client{Timeout: RequestTimeout},
+	}
+}
+
+// GetPlayer looks a player up on the hiscores of the given account type.
+func (c *HTTPClient) GetPlayer(rsn string, accountType AccountType) (*Player, error) {
+	path := accountType.Path()
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAccountType, accountType)
+	}
+
+	resp, err := c.HTTP.Get(fmt.Sprintf("%s%s?player=%s", c.BaseURL, path, url.QueryEscape(rsn)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrHiscoresUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPlayerNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %s", ErrHiscoresUnavailable, resp.Status)
+	}
+
+	return ParsePlayer(rsn, accountType, resp.Body)
+}
+
+// LookupWith returns a Lookup looking players up through a client on the hiscores of the given account type.
+func LookupWith(client Client, accountType AccountType) Lookup {
+	return func(rsn string) (*Player, error) {
+		return client.GetPlayer(rsn, accountType)
+	}
+}
@@ -0,0 +1,89 @@
+package hiscores_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores/hiscorestest"
+)
+
+func TestHTTPClientGetPlayer(t *testing.T) {
+	t.Parallel()
+
+	fixture, err := os.ReadFile("testdata/player.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := hiscorestest.NewServer()
+	defer server.Close()
+	server.SetJSON(hiscores.AccountTypeIronman, "Iron Corgi", string(fixture))
+	server.SetUnavailable("Down")
+	client := server.Client()
+
+	player, err := client.GetPlayer("iron corgi", hiscores.AccountTypeIronman)
+	if err != nil {
+		t.Fatalf("Expected player to be found, got %v", err)
+	}
+	if player.AccountType != hiscores.AccountTypeIronman {
+		t.Errorf("Expected account type ironman, got %s", player.AccountType)
+	}
+	if skill, _ := player.GetSkill("attack"); skill.Level != 99 || skill.Xp != 13034431 {
+		t.Errorf("Unexpected attack %+v", skill)
+	}
+	if activity, _ := player.GetActivity("clue_scrolls_all"); activity.Rank != 1520 || activity.Score != 312 {
+		t.Errorf("Unexpected clue scrolls %+v", activity)
+	}
+	if activity, _ := player.GetActivity("kreearra"); activity.Rank != 5120 || activity.Score != 230 {
+		t.Errorf("Unexpected Kree'Arra %+v", activity)
+	}
+	if activity, _ := player.GetActivity("tombs_of_amascut_expert"); activity.Rank != 811 || activity.Score != 17 {
+		t.Errorf("Unexpected Tombs of Amascut: Expert Mode %+v", activity)
+	}
+	if _, ok := player.GetActivity("zulrah"); ok {
+		t.Errorf("Expected activities missing from the response to be left out")
+	}
+
+	if _, err := client.GetPlayer("Iron Corgi", hiscores.AccountTypeNormal); err != hiscores.ErrPlayerNotFound {
+		t.Errorf("Expected player to be missing from the regular hiscores, got %v", err)
+	}
+	if _, err := client.GetPlayer("Down", hiscores.AccountTypeNormal); !errors.Is(err, hiscores.ErrHiscoresUnavailable) {
+		t.Errorf("Expected hiscores to be unavailable, got %v", err)
+	}
+}
+
+func TestCachingClient(t *testing.T) {
+	t.Parallel()
+
+	server := hiscorestest.NewServer()
+	defer server.Close()
+	server.SetXp("Corgi", map[string]int64{"attack": 1000})
+	server.SetUnavailable("Down")
+	client := hiscores.NewCachingClient(server.Client(), time.Minute)
+
+	for _, rsn := range []string{"Corgi", "corgi", "Missing", "missing", "Down", "Down"} {
+		client.GetPlayer(rsn, hiscores.AccountTypeNormal)
+	}
+
+	if n := server.Requests(hiscores.AccountTypeNormal, "Corgi"); n != 1 {
+		t.Errorf("Expected found player to be requested once, got %d", n)
+	}
+	if n := server.Requests(hiscores.AccountTypeNormal, "Missing"); n != 1 {
+		t.Errorf("Expected missing player to be requested once, got %d", n)
+	}
+	if n := server.Requests(hiscores.AccountTypeNormal, "Down"); n != 2 {
+		t.Errorf("Expected unavailable lookups not to be cached, got %d requests", n)
+	}
+
+	client.Forget("Corgi", hiscores.AccountTypeNormal)
+	player, err := client.GetPlayer("Corgi", hiscores.AccountTypeNormal)
+	if err != nil || server.Requests(hiscores.AccountTypeNormal, "Corgi") != 2 {
+		t.Errorf("Expected forgotten player to be requested again, got %v", err)
+	}
+	if skill, _ := player.GetSkill("attack"); skill.Level != 9 || skill.Xp != 1000 {
+		t.Errorf("Unexpected attack %+v", skill)
+	}
+}
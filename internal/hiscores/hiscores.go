@@ -0,0 +1,155 @@
+package hiscores
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+var ErrPlayerNotFound error = errors.New("player is not on the hiscores")
+var ErrHiscoresUnavailable error = errors.New("hiscores are unavailable")
+
+// SKILLS is the list of skills in the order they appear on the hiscores. Every one of them must be in a response.
+var SKILLS []string = []string{"overall", "attack", "defence", "strength", "hitpoints", "ranged", "prayer", "magic", "cooking", "woodcutting", "fletching", "fishing", "firemaking", "crafting", "smithing", "mining", "herblore", "agility", "thieving", "slayer", "farming", "runecraft", "hunter", "construction"}
+
+// ACTIVITIES is the list of activities, minigames and bosses that can be tracked, by the key their hiscores name maps
+// to. Responses are parsed by name, so activities Jagex adds later are parsed too but cannot be tracked until they are
+// listed here.
+var ACTIVITIES []string = []string{"grid_points", "league_points", "deadman_points", "bounty_hunter_hunter", "bounty_hunter_rogue", "bounty_hunter_legacy_hunter", "bounty_hunter_legacy_rogue", "clue_scrolls_all", "clue_scrolls_beginner", "clue_scrolls_easy", "clue_scrolls_medium", "clue_scrolls_hard", "clue_scrolls_elite", "clue_scrolls_master", "lms_rank", "pvp_arena_rank", "soul_wars_zeal", "rifts_closed", "colosseum_glory", "collections_logged", "abyssal_sire", "alchemical_hydra", "amoxliatl", "araxxor", "artio", "barrows_chests", "bryophyta", "callisto", "calvarion", "cerberus", "chambers_of_xeric", "chambers_of_xeric_challenge_mode", "chaos_elemental", "chaos_fanatic", "commander_zilyana", "corporeal_beast", "crazy_archaeologist", "dagannoth_prime", "dagannoth_rex", "dagannoth_supreme", "deranged_archaeologist", "doom_of_mokhaiotl", "duke_sucellus", "general_graardor", "giant_mole", "grotesque_guardians", "hespori", "kalphite_queen", "king_black_dragon", "kraken", "kreearra", "kril_tsutsaroth", "lunar_chests", "mimic", "nex", "nightmare", "phosanis_nightmare", "obor", "phantom_muspah", "sarachnis", "scorpia", "scurrius", "shellbane_gryphon", "skotizo", "sol_heredit", "spindel", "tempoross", "the_gauntlet", "the_corrupted_gauntlet", "the_hueycoatl", "the_leviathan", "the_royal_titans", "the_whisperer", "theatre_of_blood", "theatre_of_blood_hard_mode", "thermonuclear_smoke_devil", "tombs_of_amascut", "tombs_of_amascut_expert", "tzkal_zuk", "tztok_jad", "vardorvis", "venenatis", "vetion", "vorkath", "wintertodt", "yama", "zalcano", "zulrah"}
+
+// keyAliases maps keys derived from hiscores names to the key they were tracked by before, so stored events keep
+// working.
+var keyAliases map[string]string = map[string]string{
+	"tombs_of_amascut_expert_mode": "tombs_of_amascut_expert",
+}
+
+// Skill is a player's standing in a single skill. Unranked skills have a rank and xp of -1.
+type Skill struct {
+	Rank  int64 `json:"rank"`
+	Level int64 `json:"level"`
+	Xp    int64 `json:"xp"`
+}
+
+// Activity is a player's standing in a single activity, minigame or boss. Unranked activities have a rank and score
+// of -1.
+type Activity struct {
+	Rank  int64 `json:"rank"`
+	Score int64 `json:"score"`
+}
+
+// Player is a player's hiscores entry.
+type Player struct {
+	// RuneScapeName is the name the player was looked up by.
+	RuneScapeName string `json:"runescape_name"`
+	// AccountType is the hiscores the player was looked up on.
+	AccountType AccountType `json:"account_type"`
+	// Skills is a map of skill names to the player's standing in them.
+	Skills map[string]Skill `json:"skills"`
+	// Activities is a map of activity names to the player's standing in them.
+	Activities map[string]Activity `json:"activities"`
+}
+
+// GetPlayer looks a player up on the regular hiscores through the DefaultClient.
+// ErrPlayerNotFound is returned if the player does not exist, and an error wrapping ErrHiscoresUnavailable if the hiscores could not be reached.
+func GetPlayer(rsn string) (*Player, error) {
+	return DefaultClient.GetPlayer(rsn, AccountTypeNormal)
+}
+
+// liteResponse is a hiscores lite JSON response.
+type liteResponse struct {
+	Skills []struct {
+		Name  string `json:"name"`
+		Rank  int64  `json:"rank"`
+		Level int64  `json:"level"`
+		Xp    int64  `json:"xp"`
+	} `json:"skills"`
+	Activities []struct {
+		Name  string `json:"name"`
+		Rank  int64  `json:"rank"`
+		Score int64  `json:"score"`
+	} `json:"activities"`
+}
+
+// Key returns the key of a skill or activity by its hiscores name, e.g. "clue_scrolls_all" for "Clue Scrolls (all)".
+// Keys map to themselves.
+func Key(name string) string {
+	key := strings.Builder{}
+	separate := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '\'':
+			continue
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			if separate && key.Len() > 0 {
+				key.WriteRune('_')
+			}
+			separate = false
+			key.WriteRune(r)
+		default:
+			separate = true
+		}
+	}
+
+	if alias, ok := keyAliases[key.String()]; ok {
+		return alias
+	}
+
+	return key.String()
+}
+
+// ParsePlayer parses a hiscores lite JSON response. Skills and activities are read by name, so the response may list
+// them in any order. Every skill in SKILLS must be present, while activities missing from the response are left out.
+func ParsePlayer(rsn string, accountType AccountType, body io.Reader) (*Player, error) {
+	response := liteResponse{}
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse response: %v", ErrHiscoresUnavailable, err)
+	}
+
+	player := &Player{RuneScapeName: rsn, AccountType: accountType, Skills: make(map[string]Skill), Activities: make(map[string]Activity)}
+	for _, v := range response.Skills {
+		player.Skills[Key(v.Name)] = Skill{Rank: v.Rank, Level: v.Level, Xp: v.Xp}
+	}
+	for _, v := range response.Activities {
+		player.Activities[Key(v.Name)] = Activity{Rank: v.Rank, Score: v.Score}
+	}
+
+	for _, skill := range SKILLS {
+		if _, ok := player.Skills[skill]; !ok {
+			return nil, fmt.Errorf("%w: response is missing %s", ErrHiscoresUnavailable, skill)
+		}
+	}
+
+	return player, nil
+}
+
+// GetSkill returns the player's standing in a skill.
+func (p *Player) GetSkill(skill string) (Skill, bool) {
+	s, ok := p.Skills[skill]
+	return s, ok
+}
+
+// GetActivity returns the player's standing in an activity, minigame or boss.
+func (p *Player) GetActivity(activity string) (Activity, bool) {
+	a, ok := p.Activities[activity]
+	return a, ok
+}
+
+// CombatLevel returns the player's combat level. Unranked skills count as their starting level.
+func (p *Player) CombatLevel() int64 {
+	level := func(skill string, minimum float64) float64 {
+		if s, ok := p.Skills[skill]; ok && float64(s.Level) > minimum {
+			return float64(s.Level)
+		}
+		return minimum
+	}
+
+	base := 0.25 * (level("defence", 1) + level("hitpoints", 10) + math.Floor(level("prayer", 1)/2))
+	melee := 0.325 * (level("attack", 1) + level("strength", 1))
+	ranged := 0.325 * math.Floor(level("ranged", 1)*3/2)
+	magic := 0.325 * math.Floor(level("magic", 1)*3/2)
+
+	return int64(base + math.Max(melee, math.Max(ranged, magic)))
+}
@@ -0,0 +1,84 @@
+package hiscores
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCombatLevel(t *testing.T) {
+	t.Parallel()
+
+	maxed := &Player{Skills: map[string]Skill{}}
+	for _, skill := range SKILLS {
+		maxed.Skills[skill] = Skill{Level: 99}
+	}
+	if level := maxed.CombatLevel(); level != 126 {
+		t.Errorf("Expected maxed combat level to be 126, got %d", level)
+	}
+
+	fresh := &Player{Skills: map[string]Skill{}}
+	if level := fresh.CombatLevel(); level != 3 {
+		t.Errorf("Expected fresh combat level to be 3, got %d", level)
+	}
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]string{
+		"Overall":                           "overall",
+		"Clue Scrolls (all)":                "clue_scrolls_all",
+		"Bounty Hunter (Legacy) - Hunter":   "bounty_hunter_legacy_hunter",
+		"Chambers of Xeric: Challenge Mode": "chambers_of_xeric_challenge_mode",
+		"Kree'Arra":                         "kreearra",
+		"Phosani's Nightmare":               "phosanis_nightmare",
+		"TzKal-Zuk":                         "tzkal_zuk",
+		"Tombs of Amascut: Expert Mode":     "tombs_of_amascut_expert",
+		"clue_scrolls_all":                  "clue_scrolls_all",
+	} {
+		if key := Key(name); key != expected {
+			t.Errorf("Expected %q to have key %q, got %q", name, expected, key)
+		}
+	}
+}
+
+func TestParsePlayerRequiresEverySkill(t *testing.T) {
+	t.Parallel()
+
+	body := `{"skills":[{"name":"Overall","rank":1,"level":32,"xp":0}],"activities":[]}`
+	if _, err := ParsePlayer("Corgi", AccountTypeNormal, strings.NewReader(body)); !errors.Is(err, ErrHiscoresUnavailable) {
+		t.Errorf("Expected a response missing skills to be rejected, got %v", err)
+	}
+	if _, err := ParsePlayer("Corgi", AccountTypeNormal, strings.NewReader("1,2,3\n")); !errors.Is(err, ErrHiscoresUnavailable) {
+		t.Errorf("Expected a response that is not JSON to be rejected, got %v", err)
+	}
+}
+
+// fakeClient is a Client answering every lookup with an empty player.
+type fakeClient struct{}
+
+func (fakeClient) GetPlayer(rsn string, accountType AccountType) (*Player, error) {
+	return &Player{RuneScapeName: rsn, AccountType: accountType}, nil
+}
+
+func TestCachingClientEvictsExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	client := NewCachingClient(fakeClient{}, time.Minute)
+	client.now = func() time.Time { return now }
+
+	client.GetPlayer("Corgi", AccountTypeNormal)
+	client.GetPlayer("Shiba", AccountTypeNormal)
+	now = now.Add(2 * time.Minute)
+	client.GetPlayer("Husky", AccountTypeNormal)
+
+	if len(client.entries) != 1 {
+		t.Errorf("Expected expired entries to be evicted, got %d entries", len(client.entries))
+	}
+	if _, ok := client.entries[cacheKey("Husky", AccountTypeNormal)]; !ok {
+		t.Errorf("Expected the new entry to be cached")
+	}
+}
@@ -0,0 +1,187 @@
+// Package hiscorestest provides a local stand-in for the hiscores, serving fixture JSON responses to tests.
+package hiscorestest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+)
+
+// Server is a local HTTP server answering hiscores lite requests from fixtures.
+// Players without a fixture are answered with a 404, like the real hiscores.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	fixtures    map[string]string
+	unavailable map[string]bool
+	requests    map[string]int
+}
+
+// NewServer starts a new Server. Callers must Close it.
+func NewServer() *Server {
+	s := &Server{
+		fixtures:    make(map[string]string),
+		unavailable: make(map[string]bool),
+		requests:    make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+// fixtureKey returns the key of a player's fixture. RSNs are not case sensitive.
+func fixtureKey(accountType hiscores.AccountType, rsn string) string {
+	return string(accountType) + "/" + strings.ToLower(rsn)
+}
+
+// serve answers a hiscores lite request.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	accountType := hiscores.AccountType("")
+	for _, v := range []hiscores.AccountType{hiscores.AccountTypeNormal, hiscores.AccountTypeIronman, hiscores.AccountTypeHardcoreIronman, hiscores.AccountTypeUltimateIronman} {
+		if v.Path() == r.URL.Path {
+			accountType = v
+		}
+	}
+	if len(accountType) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	key := fixtureKey(accountType, r.URL.Query().Get("player"))
+
+	s.mu.Lock()
+	s.requests[key]++
+	fixture, ok := s.fixtures[key]
+	unavailable := s.unavailable[key]
+	s.mu.Unlock()
+
+	switch {
+	case unavailable:
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	case !ok:
+		http.NotFound(w, r)
+	default:
+		fmt.Fprint(w, fixture)
+	}
+}
+
+// Client returns a hiscores client requesting this server.
+func (s *Server) Client() *hiscores.HTTPClient {
+	return hiscores.NewHTTPClient(s.URL)
+}
+
+// Lookup returns a Lookup requesting the regular hiscores of this server.
+func (s *Server) Lookup() hiscores.Lookup {
+	return hiscores.LookupWith(s.Client(), hiscores.AccountTypeNormal)
+}
+
+// SetJSON serves a raw hiscores lite JSON response for a player.
+func (s *Server) SetJSON(accountType hiscores.AccountType, rsn string, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[fixtureKey(accountType, rsn)] = body
+	delete(s.unavailable, fixtureKey(accountType, rsn))
+}
+
+// SetXp serves a player on the regular hiscores with the given xp. Skills left out are unranked.
+func (s *Server) SetXp(rsn string, xp map[string]int64) {
+	s.SetPlayer(rsn, xp, nil)
+}
+
+// SetPlayer serves a player on the regular hiscores with the given xp and activity scores. Skills and activities left
+// out are unranked.
+func (s *Server) SetPlayer(rsn string, xp map[string]int64, scores map[string]int64) {
+	s.SetJSON(hiscores.AccountTypeNormal, rsn, FixtureJSON(xp, scores))
+}
+
+// SetUnavailable makes lookups of a player on the regular hiscores fail as if the hiscores were down.
+func (s *Server) SetUnavailable(rsn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unavailable[fixtureKey(hiscores.AccountTypeNormal, rsn)] = true
+}
+
+// Requests returns how many times a player was requested from the hiscores of the given account type.
+func (s *Server) Requests(accountType hiscores.AccountType, rsn string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[fixtureKey(accountType, rsn)]
+}
+
+// Level returns the level reached with the given xp, capped at 99.
+func Level(xp int64) int64 {
+	var points float64
+	for level := int64(1); level < 99; level++ {
+		points += math.Floor(float64(level) + 300*math.Pow(2, float64(level)/7))
+		if int64(math.Floor(points/4)) > xp {
+			return level
+		}
+	}
+
+	return 99
+}
+
+// fixtureSkill is a skill in a hiscores lite JSON response.
+type fixtureSkill struct {
+	Name  string `json:"name"`
+	Rank  int64  `json:"rank"`
+	Level int64  `json:"level"`
+	Xp    int64  `json:"xp"`
+}
+
+// fixtureActivity is an activity in a hiscores lite JSON response.
+type fixtureActivity struct {
+	Name  string `json:"name"`
+	Rank  int64  `json:"rank"`
+	Score int64  `json:"score"`
+}
+
+// FixtureJSON renders a hiscores lite JSON response with the given xp and activity scores. Skills and activities are
+// named by their keys. Skills and activities left out are unranked, and overall is the total of the other skills.
+func FixtureJSON(xp map[string]int64, scores map[string]int64) string {
+	skills := []fixtureSkill{}
+	var totalLevel, totalXp int64
+	for _, skill := range hiscores.SKILLS[1:] {
+		value, ok := xp[skill]
+		if !ok {
+			level := int64(1)
+			if skill == "hitpoints" {
+				level = 10
+			}
+			totalLevel += level
+			skills = append(skills, fixtureSkill{Name: skill, Rank: -1, Level: level, Xp: -1})
+			continue
+		}
+
+		totalLevel += Level(value)
+		totalXp += value
+		skills = append(skills, fixtureSkill{Name: skill, Rank: 1, Level: Level(value), Xp: value})
+	}
+	skills = append([]fixtureSkill{{Name: "overall", Rank: 1, Level: totalLevel, Xp: totalXp}}, skills...)
+
+	activities := []fixtureActivity{}
+	for _, activity := range hiscores.ACTIVITIES {
+		if score, ok := scores[activity]; ok {
+			activities = append(activities, fixtureActivity{Name: activity, Rank: 1, Score: score})
+		} else {
+			activities = append(activities, fixtureActivity{Name: activity, Rank: -1, Score: -1})
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"skills": skills, "activities": activities})
+	if err != nil {
+		panic(err)
+	}
+
+	return string(body)
+}
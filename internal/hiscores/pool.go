@@ -0,0 +1,129 @@
+package hiscores
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Lookup looks a player up on the hiscores.
+type Lookup func(rsn string) (*Player, error)
+
+// PoolOptions configures how LookupPlayers spreads lookups over the hiscores.
+type PoolOptions struct {
+	// Workers is the number of lookups that may be in flight at once.
+	Workers int
+	// RequestInterval is the minimum time between two requests across all workers.
+	RequestInterval time.Duration
+	// Retries is how many times a lookup is retried when the hiscores are unavailable.
+	Retries int
+	// Backoff is the delay before the first retry. It doubles with every retry.
+	Backoff time.Duration
+}
+
+// DefaultPoolOptions keeps well clear of the hiscores rate limits.
+var DefaultPoolOptions PoolOptions = PoolOptions{
+	Workers:         4,
+	RequestInterval: 250 * time.Millisecond,
+	Retries:         3,
+	Backoff:         2 * time.Second,
+}
+
+// LookupResult is the outcome of looking a single player up.
+type LookupResult struct {
+	Player *Player
+	Err    error
+}
+
+// lookupWithRetry looks a player up, retrying with exponential backoff while the hiscores are unavailable.
+func lookupWithRetry(rsn string, lookup Lookup, opts PoolOptions, limiter <-chan time.Time) (*Player, error) {
+	var player *Player
+	var err error
+	backoff := opts.Backoff
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		<-limiter
+		player, err = lookup(rsn)
+		if err == nil || !errors.Is(err, ErrHiscoresUnavailable) {
+			return player, err
+		}
+	}
+
+	return nil, err
+}
+
+// LookupPlayers looks players up through a bounded, rate limited pool of workers and returns the results by RSN.
+// progress, if set, is called after every lookup with the number of finished and total lookups. Calls never overlap,
+// arrive in order and have all been made by the time LookupPlayers returns, but they do not block the lookups.
+func LookupPlayers(rsns []string, lookup Lookup, opts PoolOptions, progress func(done int, total int)) map[string]LookupResult {
+	unique := []string{}
+	seen := make(map[string]bool)
+	for _, rsn := range rsns {
+		if !seen[rsn] {
+			seen[rsn] = true
+			unique = append(unique, rsn)
+		}
+	}
+
+	results := make(map[string]LookupResult)
+	if len(unique) == 0 {
+		return results
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	interval := opts.RequestInterval
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+
+	limiter := time.NewTicker(interval)
+	defer limiter.Stop()
+
+	// Progress is reported from its own goroutine so that a slow callback, such as a Discord message edit, never holds
+	// up the workers. The channel has room for every report, so sending never blocks.
+	reports := make(chan int, len(unique))
+	reported := make(chan struct{})
+	go func() {
+		defer close(reported)
+		for done := range reports {
+			if progress != nil {
+				progress(done, len(unique))
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rsn := range jobs {
+				player, err := lookupWithRetry(rsn, lookup, opts, limiter.C)
+
+				mu.Lock()
+				results[rsn] = LookupResult{Player: player, Err: err}
+				reports <- len(results)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, rsn := range unique {
+		jobs <- rsn
+	}
+	close(jobs)
+	wg.Wait()
+	close(reports)
+	<-reported
+
+	return results
+}
@@ -0,0 +1,105 @@
+package hiscores
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLookupPlayers(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	inFlight, maxInFlight := 0, 0
+	lookup := func(rsn string) (*Player, error) {
+		mu.Lock()
+		attempts[rsn]++
+		attempt := attempts[rsn]
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		switch rsn {
+		case "missing":
+			return nil, ErrPlayerNotFound
+		case "flaky":
+			if attempt < 3 {
+				return nil, fmt.Errorf("%w: timeout", ErrHiscoresUnavailable)
+			}
+		case "down":
+			return nil, fmt.Errorf("%w: timeout", ErrHiscoresUnavailable)
+		}
+		return &Player{RuneScapeName: rsn}, nil
+	}
+
+	opts := PoolOptions{Workers: 2, RequestInterval: time.Millisecond, Retries: 2, Backoff: time.Millisecond}
+	rsns := []string{"a", "b", "c", "a", "missing", "flaky", "down"}
+	progressCalls := 0
+	results := LookupPlayers(rsns, lookup, opts, func(done int, total int) {
+		progressCalls++
+		if total != 6 {
+			t.Errorf("Expected 6 unique lookups, got %d", total)
+		}
+	})
+
+	if len(results) != 6 || progressCalls != 6 {
+		t.Fatalf("Expected 6 results and progress calls, got %d and %d", len(results), progressCalls)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 lookups in flight, got %d", maxInFlight)
+	}
+	if results["missing"].Err != ErrPlayerNotFound || attempts["missing"] != 1 {
+		t.Errorf("Expected missing player not to be retried, got %v after %d attempts", results["missing"].Err, attempts["missing"])
+	}
+	if results["flaky"].Err != nil || attempts["flaky"] != 3 {
+		t.Errorf("Expected flaky lookup to succeed on the third attempt, got %v after %d attempts", results["flaky"].Err, attempts["flaky"])
+	}
+	if results["down"].Err == nil || attempts["down"] != 3 {
+		t.Errorf("Expected down lookup to fail after 3 attempts, got %v after %d attempts", results["down"].Err, attempts["down"])
+	}
+}
+
+func TestLookupPlayersDoesNotWaitForProgress(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	lookups := 0
+	finished := make(chan struct{})
+	lookup := func(rsn string) (*Player, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lookups++
+		if lookups == 4 {
+			close(finished)
+		}
+		return &Player{RuneScapeName: rsn}, nil
+	}
+
+	blocked := false
+	opts := PoolOptions{Workers: 2, RequestInterval: time.Millisecond}
+	LookupPlayers([]string{"a", "b", "c", "d"}, lookup, opts, func(done int, total int) {
+		if done > 1 {
+			return
+		}
+		// A slow first progress report must not stop the remaining lookups.
+		select {
+		case <-finished:
+		case <-time.After(2 * time.Second):
+			blocked = true
+		}
+	})
+
+	if blocked {
+		t.Errorf("Expected the lookups to finish while progress was being reported")
+	}
+}
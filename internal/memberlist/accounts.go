@@ -0,0 +1,194 @@
+package memberlist
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Tags of the RuneScape accounts every member is expected to have.
+const (
+	AccountLPC  = "lpc"
+	AccountXLPC = "xlpc"
+)
+
+// ACCOUNTS is the list of account tags every member is expected to have. Members may have accounts with other tags.
+var ACCOUNTS []string = []string{AccountLPC, AccountXLPC}
+
+// accountTagPattern is the shape of a valid account tag, e.g. lpc, main or iron2.
+var accountTagPattern = regexp.MustCompile(`^[a-z][a-z0-9]{0,15}$`)
+
+var ErrInvalidAccountTag error = errors.New("account tags must be 1-16 lowercase letters or digits, starting with a letter")
+
+// RuneScapeAccount is a RuneScape account of a member, identified by a tag such as lpc or main.
+type RuneScapeAccount struct {
+	// Tag identifies the account among the member's accounts.
+	Tag string `json:"tag"`
+	// RuneScapeName is the RSN of the account.
+	RuneScapeName string `json:"runescape_name"`
+	// Primary is whether this is the member's main account.
+	Primary bool `json:"primary"`
+}
+
+// RuneScapeAccounts is a list of a member's RuneScape accounts, at most one per tag.
+type RuneScapeAccounts []RuneScapeAccount
+
+// legacyRuneScapeAccounts is how accounts were stored before members could have arbitrary tags.
+type legacyRuneScapeAccounts struct {
+	LPC  string `json:"lpc"`
+	XLPC string `json:"xlpc"`
+}
+
+// UnmarshalJSON reads a list of accounts, or the fixed lpc/xlpc object stored by older versions. Valid tags in a list are
+// normalised; invalid ones are kept as they are, so that ValidateMembers can report them.
+func (a *RuneScapeAccounts) UnmarshalJSON(data []byte) error {
+	var accounts []RuneScapeAccount
+	if err := json.Unmarshal(data, &accounts); err == nil {
+		for i, v := range accounts {
+			if tag, err := NormaliseAccountTag(v.Tag); err == nil {
+				accounts[i].Tag = tag
+			}
+		}
+		*a = accounts
+		return nil
+	}
+
+	legacy := legacyRuneScapeAccounts{}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	*a = RuneScapeAccounts{}
+	if len(legacy.LPC) > 0 {
+		*a = append(*a, RuneScapeAccount{Tag: AccountLPC, RuneScapeName: legacy.LPC})
+	}
+	if len(legacy.XLPC) > 0 {
+		*a = append(*a, RuneScapeAccount{Tag: AccountXLPC, RuneScapeName: legacy.XLPC})
+	}
+
+	return nil
+}
+
+// NormaliseAccountTag lowercases an account tag and checks it is valid.
+func NormaliseAccountTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !accountTagPattern.MatchString(tag) {
+		return "", ErrInvalidAccountTag
+	}
+
+	return tag, nil
+}
+
+// Get returns the account with the given tag, or nil if there is none.
+func (a RuneScapeAccounts) Get(tag string) *RuneScapeAccount {
+	for i, v := range a {
+		if strings.EqualFold(v.Tag, tag) {
+			return &a[i]
+		}
+	}
+
+	return nil
+}
+
+// Tags returns the tags of the accounts.
+func (a RuneScapeAccounts) Tags() []string {
+	tags := []string{}
+	for _, v := range a {
+		tags = append(tags, v.Tag)
+	}
+
+	return tags
+}
+
+// Primary returns the account flagged as primary, falling back to the first account. Nil is returned if there are no accounts.
+func (a RuneScapeAccounts) Primary() *RuneScapeAccount {
+	for i, v := range a {
+		if v.Primary {
+			return &a[i]
+		}
+	}
+	if len(a) == 0 {
+		return nil
+	}
+
+	return &a[0]
+}
+
+// GetAccount returns the RSN of the member's account with the given tag, or an empty string if it is unknown.
+func (m Member) GetAccount(account string) string {
+	if v := m.Accounts.Get(account); v != nil {
+		return v.RuneScapeName
+	}
+
+	return ""
+}
+
+// SetAccount sets the RSN of the member's account with the given tag, adding the account if needed.
+// An empty RSN removes the account.
+func (m *Member) SetAccount(account string, rsn string) error {
+	tag, err := NormaliseAccountTag(account)
+	if err != nil {
+		return err
+	}
+	rsn = strings.TrimSpace(rsn)
+
+	accounts := RuneScapeAccounts{}
+	found := false
+	for _, v := range m.Accounts {
+		if v.Tag != tag {
+			accounts = append(accounts, v)
+			continue
+		}
+
+		found = true
+		if len(rsn) > 0 {
+			v.RuneScapeName = rsn
+			accounts = append(accounts, v)
+		}
+	}
+	if !found && len(rsn) > 0 {
+		accounts = append(accounts, RuneScapeAccount{Tag: tag, RuneScapeName: rsn})
+	}
+	m.Accounts = accounts
+
+	return nil
+}
+
+// SetPrimaryAccount flags the member's account with the given tag as primary, clearing the flag on their other accounts.
+func (m *Member) SetPrimaryAccount(account string) error {
+	if m.Accounts.Get(account) == nil {
+		return ErrUnknownAccount
+	}
+
+	accounts := make(RuneScapeAccounts, len(m.Accounts))
+	for i, v := range m.Accounts {
+		v.Primary = strings.EqualFold(v.Tag, account)
+		accounts[i] = v
+	}
+	m.Accounts = accounts
+
+	return nil
+}
+
+// PrimaryAccountTag returns the tag of the account the member flagged as primary, or an empty string if none is flagged.
+func (m Member) PrimaryAccountTag() string {
+	for _, v := range m.Accounts {
+		if v.Primary {
+			return v.Tag
+		}
+	}
+
+	return ""
+}
+
+// HasRuneScapeName returns whether any of the member's accounts has the given RSN, ignoring case.
+func (m Member) HasRuneScapeName(rsn string) bool {
+	for _, v := range m.Accounts {
+		if strings.EqualFold(v.RuneScapeName, rsn) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package memberlist
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSetAccount(t *testing.T) {
+	t.Parallel()
+
+	member := Member{}
+	if err := member.SetAccount("Pure", "bender pure"); err != nil {
+		t.Fatal(err)
+	}
+	if err := member.SetAccount(AccountLPC, "bender life"); err != nil {
+		t.Fatal(err)
+	}
+	if err := member.SetAccount("not a tag", "x"); err != ErrInvalidAccountTag {
+		t.Errorf("Expected ErrInvalidAccountTag, got %v", err)
+	}
+	if member.GetAccount("pure") != "bender pure" || len(member.Accounts) != 2 {
+		t.Fatalf("Expected pure and lpc accounts, got %+v", member.Accounts)
+	}
+	if primary := member.Accounts.Primary(); primary.Tag != "pure" {
+		t.Errorf("Expected the first account to be primary by default, got %s", primary.Tag)
+	}
+
+	if err := member.SetPrimaryAccount(AccountLPC); err != nil {
+		t.Fatal(err)
+	}
+	if err := member.SetPrimaryAccount("iron"); err != ErrUnknownAccount {
+		t.Errorf("Expected ErrUnknownAccount, got %v", err)
+	}
+	if primary := member.Accounts.Primary(); primary.Tag != AccountLPC {
+		t.Errorf("Expected lpc to be primary, got %s", primary.Tag)
+	}
+
+	member.SetAccount("pure", "")
+	if len(member.Accounts) != 1 || !member.HasRuneScapeName("BENDER LIFE") {
+		t.Errorf("Expected removing pure to leave lpc, got %+v", member.Accounts)
+	}
+}
+
+func TestUnmarshalLegacyAccounts(t *testing.T) {
+	t.Parallel()
+
+	member := Member{}
+	if err := json.Unmarshal([]byte(`{"name": "joey", "runescape_accounts": {"lpc": "bender life", "xlpc": ""}}`), &member); err != nil {
+		t.Fatal(err)
+	}
+	if len(member.Accounts) != 1 || member.GetAccount(AccountLPC) != "bender life" {
+		t.Errorf("Expected the legacy lpc account to be read, got %+v", member.Accounts)
+	}
+}
@@ -0,0 +1,78 @@
+package memberlist
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Finding is a problem with a memberlist entry found by CheckMembers.
+type Finding struct {
+	// Row is the 1-based sheet row of the entry, or 0 if it is not known.
+	Row int
+	// Member is the entry the problem was found on.
+	Member Member
+	// Problem is a human readable description of the problem.
+	Problem string
+}
+
+// MANDATORY_COLUMNS are the columns every memberlist entry must fill in.
+var MANDATORY_COLUMNS []string = []string{ColumnName, ColumnRank, ColumnDiscordID}
+
+// CheckMembers looks for duplicate Discord IDs and RSNs, missing UUIDs, unknown ranks and empty mandatory fields.
+// If guildMemberIDs is not nil, Discord IDs that are not in it are reported as well, except for departed members.
+// Findings are ordered by row.
+func CheckMembers(members []Member, guildMemberIDs map[string]bool) []Finding {
+	findings := []Finding{}
+	report := func(member Member, problem string, args ...interface{}) {
+		findings = append(findings, Finding{Row: member.Row, Member: member, Problem: fmt.Sprintf(problem, args...)})
+	}
+
+	discordIDs := make(map[string]Member)
+	rsns := make(map[string]Member)
+	for _, member := range members {
+		if len(member.Uuid) == 0 {
+			report(member, "missing UUID")
+		}
+
+		values := map[string]string{ColumnName: member.Name, ColumnRank: member.Rank, ColumnDiscordID: member.DiscordID}
+		for _, column := range MANDATORY_COLUMNS {
+			if len(values[column]) == 0 {
+				report(member, "empty %s", column)
+			}
+		}
+
+		if len(member.Rank) > 0 && GetRankByName(member.Rank) == nil && !member.IsDeparted() {
+			report(member, "unknown rank %q", member.Rank)
+		}
+
+		if len(member.DiscordID) > 0 {
+			if first, ok := discordIDs[member.DiscordID]; ok {
+				report(member, "Discord ID %s is also used by %s (row %d)", member.DiscordID, first.Name, first.Row)
+			} else {
+				discordIDs[member.DiscordID] = member
+			}
+
+			if guildMemberIDs != nil && !guildMemberIDs[member.DiscordID] && !member.IsDeparted() {
+				report(member, "Discord ID %s is not in the server", member.DiscordID)
+			}
+		}
+
+		for _, account := range member.Accounts {
+			key := normaliseIdentity(account.RuneScapeName)
+			if len(key) == 0 {
+				continue
+			}
+			if first, ok := rsns[key]; ok && first.Key() != member.Key() {
+				report(member, "%s RSN %s is also used by %s (row %d)", account.Tag, account.RuneScapeName, first.Name, first.Row)
+				continue
+			}
+			rsns[key] = member
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Row < findings[j].Row
+	})
+
+	return findings
+}
@@ -0,0 +1,41 @@
+package memberlist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckMembers(t *testing.T) {
+	t.Parallel()
+
+	members := []Member{
+		{Uuid: "1", Name: "joey", Rank: "Member", DiscordID: "10", Row: 2, Accounts: RuneScapeAccounts{{Tag: AccountLPC, RuneScapeName: "Bender Life"}}},
+		{Uuid: "2", Name: "copy", Rank: "Member", DiscordID: "10", Row: 3, Accounts: RuneScapeAccounts{{Tag: "main", RuneScapeName: "bender_life"}}},
+		{Name: "no uuid", Rank: "Captain", DiscordID: "30", Row: 4},
+		{Uuid: "4", Name: "gone", Rank: DepartedRankName, DiscordID: "40", Row: 5},
+		{Uuid: "5", Name: "incomplete", Row: 6},
+	}
+
+	findings := CheckMembers(members, map[string]bool{"10": true})
+
+	expected := []struct {
+		row     int
+		problem string
+	}{
+		{3, "Discord ID 10 is also used by joey (row 2)"},
+		{3, "main RSN bender_life is also used by joey (row 2)"},
+		{4, "missing UUID"},
+		{4, "unknown rank"},
+		{4, "Discord ID 30 is not in the server"},
+		{6, "empty rank"},
+		{6, "empty discord_id"},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %+v", len(expected), findings)
+	}
+	for i, v := range expected {
+		if findings[i].Row != v.row || !strings.HasPrefix(findings[i].Problem, v.problem) {
+			t.Errorf("Expected finding %d to be %q on row %d, got %q on row %d", i, v.problem, v.row, findings[i].Problem, findings[i].Row)
+		}
+	}
+}
@@ -2,15 +2,12 @@ package memberlist
 
 import (
 	"errors"
+	"strings"
+	"sync"
 
-	hiscores "github.com/joeydotdev/osrs-hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
 )
 
-type RuneScapeAccounts struct {
-	LPC  string `json:"lpc"`
-	XLPC string `json:"xlpc"`
-}
-
 // Member is a member of the clan.
 type Member struct {
 	// Uuid is the UUID of the member.
@@ -25,14 +22,53 @@ type Member struct {
 	TeamSpeakID string `json:"teamspeak_id"`
 	// RuneScapeAccounts is a list of the member's RuneScape accounts.
 	Accounts RuneScapeAccounts `json:"runescape_accounts"`
+	// Row is the 1-based sheet row the member was read from.
+	Row int `json:"-"`
+}
+
+// clone returns a copy of the member that shares no slices with it.
+func (m Member) clone() Member {
+	m.Accounts = append(RuneScapeAccounts{}, m.Accounts...)
+	return m
+}
+
+// IsDeparted returns whether the member has been marked as having left the clan.
+func (m Member) IsDeparted() bool {
+	return strings.EqualFold(m.Rank, DepartedRankName)
+}
+
+// Key returns a stable identifier for the member: their UUID, falling back to their Discord ID and then their name.
+func (m Member) Key() string {
+	if len(m.Uuid) > 0 {
+		return m.Uuid
+	}
+	if len(m.DiscordID) > 0 {
+		return m.DiscordID
+	}
+
+	return m.Name
 }
 
 type Memberlist struct {
 	// Members is a list of members.
 	Members []Member `json:"members"`
+	// Issues is a list of sheet rows that could not be parsed during the last hydration.
+	Issues []RowIssue `json:"-"`
+
+	mu       sync.RWMutex
+	schema   *SheetSchema
+	rowCount int
+	// version is bumped whenever the members are read from or written to the sheet.
+	version int
 }
 
+// DepartedRankName is the memberlist rank of members who have left the clan but are kept on the sheet.
+const DepartedRankName = "Departed"
+
 var DuplicateInMemberlistError error = errors.New("Member already exists in memberlist. Try updating instead.")
+var ErrMemberNotInMemberlist error = errors.New("member is not in the memberlist")
+var ErrMissingRSN error = errors.New("no RSN on the memberlist")
+var ErrUnknownAccount error = errors.New("unknown account")
 
 // NewMemberlist creates a new memberlist.
 func NewMemberlist() *Memberlist {
@@ -51,34 +87,61 @@ func (m *Memberlist) hydrate() error {
 		return err
 	}
 
-	for _, v := range resp.Values {
-		if len(v) < 5 {
-			continue
-		}
+	schema, members, issues, err := parseMemberlistValues(resp.Values)
+	if err != nil {
+		return err
+	}
 
-		member := Member{
-			Uuid:        v[0].(string),
-			Name:        v[1].(string),
-			DiscordID:   v[2].(string),
-			TeamSpeakID: v[3].(string),
-			Accounts: RuneScapeAccounts{
-				XLPC: v[4].(string),
-				LPC:  v[5].(string),
-			},
-			Rank: v[6].(string),
-		}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-		m.Members = append(m.Members, member)
-	}
+	m.schema = schema
+	m.Members = members
+	m.Issues = issues
+	m.rowCount = len(resp.Values)
+	m.version++
 
 	return nil
 }
 
+// Refresh re-reads the memberlist from the data store.
+func (m *Memberlist) Refresh() error {
+	return m.hydrate()
+}
+
+// Version returns the current version of the memberlist, which changes whenever it is re-read or written.
+func (m *Memberlist) Version() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.version
+}
+
+// GetIssues returns the sheet rows that could not be parsed during the last hydration.
+func (m *Memberlist) GetIssues() []RowIssue {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]RowIssue{}, m.Issues...)
+}
+
+// GetSchema returns the sheet schema read during the last hydration, or nil if the memberlist was never loaded.
+func (m *Memberlist) GetSchema() *SheetSchema {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.schema
+}
+
 // GetMemberByName gets a member from the memberlist by their name.
 func (m *Memberlist) GetMemberByName(name string) *Member {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for _, v := range m.Members {
 		if v.Name == name {
-			return &v
+			member := v.clone()
+			return &member
 		}
 	}
 	return nil
@@ -86,64 +149,264 @@ func (m *Memberlist) GetMemberByName(name string) *Member {
 
 // GetMemberByDiscordID gets a member from the memberlist by their Discord ID.
 func (m *Memberlist) GetMemberByDiscordID(discordId string) *Member {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for _, v := range m.Members {
 		if v.DiscordID == discordId {
-			return &v
+			member := v.clone()
+			return &member
 		}
 	}
 	return nil
 }
 
-// GetMemberByRuneScapeName gets a member from the memberlist by their RuneScape name.
+// GetMemberByKey gets a member from the memberlist by the identifier returned from Member.Key.
+func (m *Memberlist) GetMemberByKey(key string) *Member {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, v := range m.Members {
+		if v.Key() == key {
+			member := v.clone()
+			return &member
+		}
+	}
+	return nil
+}
+
+// GetMemberByRuneScapeName gets a member from the memberlist by the RuneScape name of any of their accounts, ignoring case.
 func (m *Memberlist) GetMemberByRuneScapeName(runescapeName string) *Member {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for _, v := range m.Members {
-		if v.Accounts.LPC == runescapeName || v.Accounts.XLPC == runescapeName {
-			return &v
+		if v.HasRuneScapeName(runescapeName) {
+			member := v.clone()
+			return &member
 		}
 	}
 	return nil
 }
 
-// GetMembersWithInvalidXLPCRSNs gets a list of members with invalid XLPC RSNs.
-func (m *Memberlist) GetMembersWithInvalidXLPCRSNs() []Member {
-	hiscores := hiscores.NewHiscores()
-	var members []Member
+// FindMember looks a member up by Discord mention or ID, name, or RuneScape name, ignoring case.
+func (m *Memberlist) FindMember(query string) *Member {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, "<@") && strings.HasSuffix(query, ">") {
+		query = strings.TrimPrefix(strings.TrimSuffix(query[2:], ">"), "!")
+	}
+	if len(query) == 0 {
+		return nil
+	}
+
+	if member := m.GetMemberByDiscordID(query); member != nil {
+		return member
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
 	for _, v := range m.Members {
-		if len(v.Accounts.XLPC) == 0 {
-			members = append(members, v)
-			continue
+		if strings.EqualFold(v.Name, query) {
+			member := v.clone()
+			return &member
+		}
+	}
+	for _, v := range m.Members {
+		if v.HasRuneScapeName(query) {
+			member := v.clone()
+			return &member
+		}
+	}
+
+	return nil
+}
+
+// InvalidRSN is a member account that failed RSN validation.
+type InvalidRSN struct {
+	// Member is the member owning the account.
+	Member Member
+	// Account is the tag of the account, e.g. lpc.
+	Account string
+	// RuneScapeName is the RSN on the memberlist.
+	RuneScapeName string
+	// Err is ErrMissingRSN, hiscores.ErrPlayerNotFound, or an error wrapping hiscores.ErrHiscoresUnavailable.
+	Err error
+}
+
+// selectAccounts returns the tags of a member's accounts to validate. With no tags given, every account the member has
+// is selected, or the expected ACCOUNTS if they have none.
+func selectAccounts(member Member, accounts []string) []string {
+	if len(accounts) > 0 {
+		return accounts
+	}
+	if len(member.Accounts) == 0 {
+		return ACCOUNTS
+	}
+
+	return member.Accounts.Tags()
+}
+
+// ValidateRSNs looks the accounts with the given tags of every member up on the hiscores and returns those that could not
+// be validated. If no tags are given, every account of every member is validated.
+// progress, if set, is reported as hiscores lookups finish.
+func (m *Memberlist) ValidateRSNs(accounts []string, lookup hiscores.Lookup, progress func(done int, total int)) []InvalidRSN {
+	return m.validateRSNs(accounts, lookup, hiscores.DefaultPoolOptions, progress)
+}
+
+// validateRSNs validates RSNs like ValidateRSNs, spreading the lookups over the hiscores as opts allow.
+func (m *Memberlist) validateRSNs(accounts []string, lookup hiscores.Lookup, opts hiscores.PoolOptions, progress func(done int, total int)) []InvalidRSN {
+	members := m.GetMembers()
+	rsns := []string{}
+	for _, v := range members {
+		for _, account := range selectAccounts(v, accounts) {
+			if rsn := v.GetAccount(account); len(rsn) > 0 {
+				rsns = append(rsns, rsn)
+			}
 		}
+	}
+
+	results := hiscores.LookupPlayers(rsns, lookup, opts, progress)
+
+	invalid := []InvalidRSN{}
+	for _, v := range members {
+		for _, account := range selectAccounts(v, accounts) {
+			rsn := v.GetAccount(account)
+			if len(rsn) == 0 {
+				invalid = append(invalid, InvalidRSN{Member: v, Account: account, Err: ErrMissingRSN})
+				continue
+			}
+
+			if result := results[rsn]; result.Err != nil {
+				invalid = append(invalid, InvalidRSN{Member: v, Account: account, RuneScapeName: rsn, Err: result.Err})
+			}
+		}
+	}
+
+	return invalid
+}
 
-		overallLevel, err := hiscores.GetPlayerSkillLevel(v.Accounts.XLPC, "overall")
-		if err != nil || overallLevel < 0 {
-			members = append(members, v)
+// getMembersWithInvalidRSNs gets a list of members whose account is missing or not on the hiscores.
+// Members that could not be checked because the hiscores are unavailable are not included.
+func (m *Memberlist) getMembersWithInvalidRSNs(account string) []Member {
+	var members []Member
+	for _, v := range m.ValidateRSNs([]string{account}, hiscores.GetPlayer, nil) {
+		if v.Err == ErrMissingRSN || errors.Is(v.Err, hiscores.ErrPlayerNotFound) {
+			members = append(members, v.Member)
 		}
 	}
 
 	return members
 }
 
+// GetMembersWithInvalidXLPCRSNs gets a list of members with invalid XLPC RSNs.
+func (m *Memberlist) GetMembersWithInvalidXLPCRSNs() []Member {
+	return m.getMembersWithInvalidRSNs(AccountXLPC)
+}
+
 // GetMembersWithInvalidLPCRSNs gets a list of members with invalid LPC RSNs.
 func (m *Memberlist) GetMembersWithInvalidLPCRSNs() []Member {
-	hiscores := hiscores.NewHiscores()
-	var members []Member
+	return m.getMembersWithInvalidRSNs(AccountLPC)
+}
+
+// ModifyMember changes the member with the given key, see Member.Key, and writes the change to the sheet. update is
+// called with the current member while the memberlist is locked, so it can check the member has not changed since it
+// was last read; if it returns an error, nothing is changed. If the sheet cannot be written the previous member is
+// restored.
+func (m *Memberlist) ModifyMember(key string, update func(Member) (Member, error)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := -1
+	for j, v := range m.Members {
+		if v.Key() == key {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return ErrMemberNotInMemberlist
+	}
+
+	previous := m.Members[i]
+	updated, err := update(previous.clone())
+	if err != nil {
+		return err
+	}
+	m.Members[i] = updated
+	if err := writeMemberlistSheet(m); err != nil {
+		m.Members[i] = previous
+		return err
+	}
+
+	return nil
+}
+
+// AddMember appends a new member to the memberlist and writes it to the sheet.
+// A member sharing a Discord ID or name with an existing member is rejected.
+func (m *Memberlist) AddMember(member Member) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	for _, v := range m.Members {
-		if len(v.Accounts.LPC) == 0 {
-			members = append(members, v)
-			continue
+		if strings.EqualFold(v.Name, member.Name) || (len(member.DiscordID) > 0 && v.DiscordID == member.DiscordID) {
+			return DuplicateInMemberlistError
 		}
+	}
+
+	previous := m.Members
+	m.Members = append(append([]Member{}, m.Members...), member)
+	if err := writeMemberlistSheet(m); err != nil {
+		m.Members = previous
+		return err
+	}
+
+	return nil
+}
 
-		overallLevel, err := hiscores.GetPlayerSkillLevel(v.Accounts.LPC, "overall")
-		if err != nil || overallLevel < 0 {
-			members = append(members, v)
+// indexOf returns the index of a member in the memberlist, matching on UUID or, for members without one, on name.
+// The caller must hold the memberlist lock.
+func (m *Memberlist) indexOf(member Member) int {
+	for i, v := range m.Members {
+		if len(member.Uuid) > 0 && v.Uuid == member.Uuid {
+			return i
+		}
+		if len(member.Uuid) == 0 && len(v.Uuid) == 0 && v.Name == member.Name {
+			return i
 		}
 	}
+	return -1
+}
 
-	return members
+// RemoveMember removes a member from the memberlist and writes it to the sheet.
+func (m *Memberlist) RemoveMember(member Member) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(member)
+	if i < 0 {
+		return ErrMemberNotInMemberlist
+	}
+
+	previous := m.Members
+	m.Members = append(append([]Member{}, m.Members[:i]...), m.Members[i+1:]...)
+	if err := writeMemberlistSheet(m); err != nil {
+		m.Members = previous
+		return err
+	}
+
+	return nil
 }
 
+// GetMembers returns a copy of the members in the memberlist. Changes to it are not written back; see ModifyMember.
 func (m *Memberlist) GetMembers() []Member {
-	return m.Members
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	members := make([]Member, len(m.Members))
+	for i, v := range m.Members {
+		members[i] = v.clone()
+	}
+
+	return members
 }
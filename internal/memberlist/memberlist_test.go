@@ -0,0 +1,62 @@
+package memberlist
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores/hiscorestest"
+)
+
+func TestValidateRSNsAgainstStubHiscores(t *testing.T) {
+	t.Parallel()
+
+	server := hiscorestest.NewServer()
+	defer server.Close()
+	server.SetXp("Corgi", map[string]int64{"attack": 1000})
+	server.SetXp("Iron Corgi", map[string]int64{"attack": 1000})
+	server.SetUnavailable("Flaky")
+
+	opts := hiscores.PoolOptions{Workers: 2}
+	m := &Memberlist{Members: []Member{
+		{Name: "Corgi", Accounts: RuneScapeAccounts{{Tag: AccountLPC, RuneScapeName: "Corgi"}, {Tag: AccountXLPC, RuneScapeName: "Iron Corgi"}}},
+		{Name: "Renamed", Accounts: RuneScapeAccounts{{Tag: AccountLPC, RuneScapeName: "Old Name"}}},
+		{Name: "Flaky", Accounts: RuneScapeAccounts{{Tag: AccountLPC, RuneScapeName: "Flaky"}}},
+	}}
+
+	invalid := m.validateRSNs([]string{AccountLPC}, server.Lookup(), opts, nil)
+	if len(invalid) != 2 {
+		t.Fatalf("Expected 2 invalid RSNs, got %v", invalid)
+	}
+	if invalid[0].Member.Name != "Renamed" || !errors.Is(invalid[0].Err, hiscores.ErrPlayerNotFound) {
+		t.Errorf("Expected Renamed to be missing from the hiscores, got %+v", invalid[0])
+	}
+	if invalid[1].Member.Name != "Flaky" || !errors.Is(invalid[1].Err, hiscores.ErrHiscoresUnavailable) {
+		t.Errorf("Expected Flaky to be unavailable, got %+v", invalid[1])
+	}
+
+	invalid = m.validateRSNs([]string{AccountXLPC}, server.Lookup(), opts, nil)
+	if len(invalid) != 2 || invalid[0].Err != ErrMissingRSN || invalid[1].Err != ErrMissingRSN {
+		t.Errorf("Expected members without an xlpc account to be missing an RSN, got %v", invalid)
+	}
+}
+
+func TestModifyMemberLeavesMemberlistAloneOnError(t *testing.T) {
+	t.Parallel()
+
+	m := &Memberlist{Members: []Member{{Uuid: "1", Name: "joey", TeamSpeakID: "old="}}}
+	stale := errors.New("stale")
+	err := m.ModifyMember("1", func(member Member) (Member, error) {
+		member.TeamSpeakID = "new="
+		return member, stale
+	})
+	if err != stale {
+		t.Fatalf("Expected the update's error, got %v", err)
+	}
+	if member := m.GetMemberByKey("1"); member.TeamSpeakID != "old=" {
+		t.Errorf("Expected the member to be unchanged, got %+v", member)
+	}
+	if err := m.ModifyMember("2", func(member Member) (Member, error) { return member, nil }); err != ErrMemberNotInMemberlist {
+		t.Errorf("Expected ErrMemberNotInMemberlist, got %v", err)
+	}
+}
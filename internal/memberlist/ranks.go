@@ -1,61 +1,122 @@
 package memberlist
 
 import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// RANKS_CONFIG_ENV is the environment variable that may hold a base64 encoded rank ladder replacing ranks.json.
+const RANKS_CONFIG_ENV = "RANKS_JSON_BASE64"
+
+// defaultRanksConfig is the rank ladder used unless RANKS_CONFIG_ENV is set.
+//
+//go:embed ranks.json
+var defaultRanksConfig []byte
+
 type Rank struct {
-	Name   string
-	RoleID string
+	// Name is the name of the rank, as written on the memberlist.
+	Name string `json:"name"`
+	// RoleID is the ID of the Discord role of the rank.
+	RoleID string `json:"role_id"`
+	// IsMember is whether holders of the rank count as clan members, e.g. when checking event signups.
+	IsMember bool `json:"is_member"`
+	// ReceivesMassPM is whether holders of the rank are sent mass PMs.
+	ReceivesMassPM bool `json:"receives_mass_pm"`
+	// IsOfficer is whether holders of the rank may manage the clan through the bot.
+	IsOfficer bool `json:"is_officer"`
+}
+
+// RANKS is the rank ladder, ordered from the highest rank to the lowest.
+var RANKS []Rank
+
+func init() {
+	config := defaultRanksConfig
+	if encoded := os.Getenv(RANKS_CONFIG_ENV); len(encoded) > 0 {
+		var err error
+		config, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	ranks, err := ParseRanks(config)
+	if err != nil {
+		panic(err)
+	}
+	RANKS = ranks
+}
+
+// ParseRanks parses a JSON rank ladder, ordered from the highest rank to the lowest.
+func ParseRanks(config []byte) ([]Rank, error) {
+	ranks := []Rank{}
+	if err := json.Unmarshal(config, &ranks); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, errors.New("rank ladder is empty")
+	}
+
+	names := make(map[string]bool)
+	roleIDs := make(map[string]bool)
+	for _, v := range ranks {
+		name := strings.ToLower(strings.TrimSpace(v.Name))
+		if len(name) == 0 || len(v.RoleID) == 0 {
+			return nil, errors.New("every rank needs a name and a role ID")
+		}
+		if names[name] || roleIDs[v.RoleID] {
+			return nil, fmt.Errorf("rank %s is duplicated", v.Name)
+		}
+		names[name] = true
+		roleIDs[v.RoleID] = true
+	}
+
+	return ranks, nil
+}
+
+// position returns the index of a rank in RANKS, or -1 if it is not on the ladder.
+func (r Rank) position() int {
+	for i, v := range RANKS {
+		if v.RoleID == r.RoleID {
+			return i
+		}
+	}
+
+	return -1
 }
 
-var RANKS []Rank = []Rank{
-	{
-		Name:   "Leader",
-		RoleID: "692876249118539817",
-	},
-	{
-		Name:   "High Council",
-		RoleID: "692879184024043540",
-	},
-	{
-		Name:   "Council",
-		RoleID: "692879285417017375",
-	},
-	{
-		Name:   "Leadership",
-		RoleID: "817499802148274226",
-	},
-	{
-		Name:   "Officer",
-		RoleID: "692879600380018699",
-	},
-	{
-		Name:   "Legend",
-		RoleID: "692879942777569312",
-	},
-	{
-		Name:   "Old School",
-		RoleID: "1024119526801023006",
-	},
-	{
-		Name:   "Veteran",
-		RoleID: "692880299855446106",
-	},
-	{
-		Name:   "Advanced",
-		RoleID: "699354924185682031",
-	},
-	{
-		Name:   "Member",
-		RoleID: "692880390440091659",
-	},
-	{
-		Name:   "Applicant",
-		RoleID: "773216677423874048",
-	},
+// AtLeast returns whether the rank is the same as or higher than another rank. Ranks not on the ladder are never at least anything.
+func (r Rank) AtLeast(other Rank) bool {
+	position, otherPosition := r.position(), other.position()
+	if position < 0 || otherPosition < 0 {
+		return false
+	}
+
+	// RANKS is ordered from the highest rank to the lowest.
+	return position <= otherPosition
+}
+
+// Above returns whether the rank is higher than another rank.
+func (r Rank) Above(other Rank) bool {
+	return r.AtLeast(other) && r.RoleID != other.RoleID
+}
+
+// RanksAbove returns the ranks higher than the given rank, from the highest to the lowest.
+func RanksAbove(rank Rank) []Rank {
+	ranks := []Rank{}
+	for _, v := range RANKS {
+		if v.Above(rank) {
+			ranks = append(ranks, v)
+		}
+	}
+
+	return ranks
 }
 
 var ErrMemberNotInClan error = errors.New("discord member is not in the clan")
@@ -76,3 +137,33 @@ func GetDiscordMemberClanRank(member *discordgo.Member) (*Rank, error) {
 
 	return nil, ErrMemberNotInClan
 }
+
+// GetRankByName returns the rank with the given name, ignoring case. If no rank matches, nil is returned.
+func GetRankByName(name string) *Rank {
+	for _, v := range RANKS {
+		if strings.EqualFold(v.Name, strings.TrimSpace(name)) {
+			return &v
+		}
+	}
+
+	return nil
+}
+
+// GetAdjacentRank returns the rank a number of steps above the given rank in RANKS. Negative steps move down the ladder.
+// If the rank is unknown or the step leaves the ladder, nil is returned.
+func GetAdjacentRank(rank Rank, steps int) *Rank {
+	for i, v := range RANKS {
+		if v.RoleID != rank.RoleID {
+			continue
+		}
+
+		// RANKS is ordered from the highest rank to the lowest.
+		target := i - steps
+		if target < 0 || target >= len(RANKS) {
+			return nil
+		}
+		return &RANKS[target]
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package memberlist
+
+import (
+	"testing"
+)
+
+func TestRankComparison(t *testing.T) {
+	t.Parallel()
+
+	officer := GetRankByName("Officer")
+	member := GetRankByName("Member")
+	leader := GetRankByName("Leader")
+	unknown := Rank{Name: "Guest", RoleID: "0"}
+
+	if !officer.AtLeast(*member) || !officer.AtLeast(*officer) || member.AtLeast(*officer) {
+		t.Error("Expected Officer to be at least Member and itself, and Member not to be at least Officer")
+	}
+	if officer.Above(*officer) || !leader.Above(*officer) {
+		t.Error("Expected a rank not to be above itself and Leader to be above Officer")
+	}
+	if unknown.AtLeast(*member) || member.AtLeast(unknown) {
+		t.Error("Expected ranks off the ladder never to compare")
+	}
+
+	above := RanksAbove(*officer)
+	if len(above) != 4 || above[0].Name != "Leader" || above[3].Name != "Leadership" {
+		t.Errorf("Expected the 4 ranks above Officer from the highest down, got %+v", above)
+	}
+	if !officer.IsOfficer || member.IsOfficer || GetRankByName("Applicant").ReceivesMassPM {
+		t.Error("Expected rank attributes to be read from ranks.json")
+	}
+}
+
+func TestParseRanks(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseRanks([]byte(`[]`)); err == nil {
+		t.Error("Expected an empty ladder to be rejected")
+	}
+	if _, err := ParseRanks([]byte(`[{"name": "Member", "role_id": "1"}, {"name": "member", "role_id": "2"}]`)); err == nil {
+		t.Error("Expected duplicate rank names to be rejected")
+	}
+
+	ranks, err := ParseRanks([]byte(`[{"name": "Leader", "role_id": "1", "is_officer": true}, {"name": "Member", "role_id": "2", "is_member": true}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranks) != 2 || !ranks[0].IsOfficer || !ranks[1].IsMember {
+		t.Errorf("Expected attributes to be parsed, got %+v", ranks)
+	}
+}
@@ -0,0 +1,406 @@
+package memberlist
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Column keys of the memberlist sheet. Header cells are normalised and matched against these keys.
+const (
+	ColumnUuid        = "uuid"
+	ColumnName        = "name"
+	ColumnDiscordID   = "discord_id"
+	ColumnTeamSpeakID = "teamspeak_id"
+	ColumnRank        = "rank"
+	// ColumnPrimaryAccount holds the tag of the member's primary account. It is optional.
+	ColumnPrimaryAccount = "primary_account"
+)
+
+// AccountColumnSuffix marks a column holding the RSNs of the accounts with a tag, e.g. "main_rsn" for main.
+// The columns of the ACCOUNTS tags may also be named after the bare tag, e.g. "lpc".
+const AccountColumnSuffix = "_rsn"
+
+// MAXIMUM_SHEET_COLUMNS is the number of columns in MEMBERLIST_SHEET_READ_RANGE.
+const MAXIMUM_SHEET_COLUMNS = 26
+
+// COLUMNS is the list of column keys the memberlist expects, besides the account columns.
+var COLUMNS []string = []string{
+	ColumnUuid,
+	ColumnName,
+	ColumnDiscordID,
+	ColumnTeamSpeakID,
+	ColumnRank,
+}
+
+// columnAliases maps alternative header names onto column keys.
+var columnAliases map[string]string = map[string]string{
+	"id":          ColumnUuid,
+	"discord":     ColumnDiscordID,
+	"discordid":   ColumnDiscordID,
+	"teamspeak":   ColumnTeamSpeakID,
+	"teamspeakid": ColumnTeamSpeakID,
+	"ts_id":       ColumnTeamSpeakID,
+	"primary":     ColumnPrimaryAccount,
+}
+
+var ErrMissingNameColumn error = errors.New("memberlist sheet header has no name column")
+var ErrTooManyColumns error = fmt.Errorf("memberlist sheet cannot have more than %d columns", MAXIMUM_SHEET_COLUMNS)
+
+// SheetSchema maps column keys and account tags to their position in the memberlist sheet, as described by the header row.
+type SheetSchema struct {
+	columns  map[string]int
+	accounts map[string]int
+	header   []interface{}
+	width    int
+	// headerChanged is set when columns were added that are not yet in the sheet's header row.
+	headerChanged bool
+}
+
+// RowIssue describes a memberlist sheet row that could not be parsed into a member.
+type RowIssue struct {
+	// Row is the 1-based row number in the sheet.
+	Row int
+	// Reason is a human readable explanation of why the row was rejected.
+	Reason string
+	// Values are the raw cell values of the row, kept so the row survives a write back to the sheet.
+	Values []interface{}
+}
+
+// normaliseHeader turns a header cell such as "Discord ID" into a column key such as "discord_id".
+func normaliseHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(header))
+	header = strings.Join(strings.FieldsFunc(header, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '(' || r == ')'
+	}), "_")
+
+	if alias, ok := columnAliases[header]; ok {
+		return alias
+	}
+
+	return header
+}
+
+// accountTagFromColumn returns the account tag held by a column, or an empty string if it is not an account column.
+func accountTagFromColumn(key string) string {
+	for _, account := range ACCOUNTS {
+		if key == account {
+			return account
+		}
+	}
+	if !strings.HasSuffix(key, AccountColumnSuffix) {
+		return ""
+	}
+
+	tag, err := NormaliseAccountTag(strings.TrimSuffix(key, AccountColumnSuffix))
+	if err != nil {
+		return ""
+	}
+
+	return tag
+}
+
+// accountColumnHeader returns the header of a new column holding the RSNs of the accounts with a tag.
+func accountColumnHeader(tag string) string {
+	return strings.ToUpper(tag) + " RSN"
+}
+
+// NewSheetSchema builds a SheetSchema from the header row of the memberlist sheet.
+func NewSheetSchema(header []interface{}) (*SheetSchema, error) {
+	schema := &SheetSchema{
+		columns:  make(map[string]int),
+		accounts: make(map[string]int),
+		header:   append([]interface{}{}, header...),
+		width:    len(header),
+	}
+
+	for i, cell := range header {
+		key := normaliseHeader(cellString(cell))
+		if len(key) == 0 {
+			continue
+		}
+
+		if tag := accountTagFromColumn(key); len(tag) > 0 {
+			if _, ok := schema.accounts[tag]; ok {
+				return nil, fmt.Errorf("memberlist sheet header has a duplicate %s account column", tag)
+			}
+			schema.accounts[tag] = i
+			continue
+		}
+
+		if _, ok := schema.columns[key]; ok {
+			return nil, fmt.Errorf("memberlist sheet header has a duplicate %s column", key)
+		}
+		schema.columns[key] = i
+	}
+
+	if _, ok := schema.columns[ColumnName]; !ok {
+		return nil, ErrMissingNameColumn
+	}
+
+	return schema, nil
+}
+
+// defaultSheetSchema returns a schema with the columns laid out in the order of COLUMNS, followed by the primary account
+// column and a column for every account tag used by the members.
+func defaultSheetSchema(members []Member) *SheetSchema {
+	header := []interface{}{}
+	for _, column := range COLUMNS {
+		header = append(header, column)
+	}
+	header = append(header, ColumnPrimaryAccount)
+	for _, account := range ACCOUNTS {
+		header = append(header, account+AccountColumnSuffix)
+	}
+
+	// NewSheetSchema cannot fail on a header containing the name column and no duplicates.
+	schema, _ := NewSheetSchema(header)
+	for _, member := range members {
+		schema.ensureColumns(member)
+	}
+
+	return schema
+}
+
+// Header returns the header row of the sheet, including columns added since it was read.
+func (s *SheetSchema) Header() []interface{} {
+	return s.header
+}
+
+// AccountTags returns the tags of the account columns in column order.
+func (s *SheetSchema) AccountTags() []string {
+	tags := []string{}
+	for tag := range s.accounts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return s.accounts[tags[i]] < s.accounts[tags[j]]
+	})
+
+	return tags
+}
+
+// addColumn appends a column to the sheet and returns its index.
+func (s *SheetSchema) addColumn(header string) (int, error) {
+	if s.width >= MAXIMUM_SHEET_COLUMNS {
+		return 0, ErrTooManyColumns
+	}
+
+	for len(s.header) < s.width {
+		s.header = append(s.header, "")
+	}
+	s.header = append(s.header, header)
+	s.width++
+	s.headerChanged = true
+
+	return s.width - 1, nil
+}
+
+// ensureColumns adds the columns needed to store a member: one for each of their account tags and, if they flagged a
+// primary account, the primary account column.
+func (s *SheetSchema) ensureColumns(member Member) error {
+	for _, account := range member.Accounts {
+		if _, ok := s.accounts[account.Tag]; ok {
+			continue
+		}
+		i, err := s.addColumn(accountColumnHeader(account.Tag))
+		if err != nil {
+			return err
+		}
+		s.accounts[account.Tag] = i
+	}
+
+	if _, ok := s.columns[ColumnPrimaryAccount]; ok {
+		return nil
+	}
+	if len(member.PrimaryAccountTag()) == 0 {
+		return nil
+	}
+	i, err := s.addColumn("Primary Account")
+	if err != nil {
+		return err
+	}
+	s.columns[ColumnPrimaryAccount] = i
+
+	return nil
+}
+
+// MissingColumns returns the expected column keys and account tags that are absent from the header.
+func (s *SheetSchema) MissingColumns() []string {
+	missing := []string{}
+	for _, column := range COLUMNS {
+		if _, ok := s.columns[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	for _, account := range ACCOUNTS {
+		if _, ok := s.accounts[account]; !ok {
+			missing = append(missing, account)
+		}
+	}
+
+	return missing
+}
+
+// Get returns the trimmed value of a column in a row, or an empty string if the row is too short or the column is unknown.
+func (s *SheetSchema) Get(row []interface{}, column string) string {
+	i, ok := s.columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(cellString(row[i]))
+}
+
+// readAccounts reads the account columns of a row. The account named by the primary account column is flagged as primary.
+func (s *SheetSchema) readAccounts(row []interface{}) RuneScapeAccounts {
+	primary := strings.ToLower(s.Get(row, ColumnPrimaryAccount))
+	accounts := RuneScapeAccounts{}
+	for _, tag := range s.AccountTags() {
+		i := s.accounts[tag]
+		if i >= len(row) {
+			continue
+		}
+		rsn := strings.TrimSpace(cellString(row[i]))
+		if len(rsn) == 0 {
+			continue
+		}
+		accounts = append(accounts, RuneScapeAccount{Tag: tag, RuneScapeName: rsn, Primary: tag == primary})
+	}
+
+	return accounts
+}
+
+// Row serialises a member into sheet cell values following the header order.
+// Accounts without a column are left out; see ensureColumns.
+func (s *SheetSchema) Row(member Member) []interface{} {
+	row := make([]interface{}, s.width)
+	for i := range row {
+		row[i] = ""
+	}
+
+	values := map[string]string{
+		ColumnUuid:        member.Uuid,
+		ColumnName:        member.Name,
+		ColumnDiscordID:   member.DiscordID,
+		ColumnTeamSpeakID: member.TeamSpeakID,
+		ColumnRank:        member.Rank,
+	}
+	if primary := member.PrimaryAccountTag(); len(primary) > 0 {
+		values[ColumnPrimaryAccount] = primary
+	}
+	for column, value := range values {
+		if i, ok := s.columns[column]; ok {
+			row[i] = value
+		}
+	}
+	for _, account := range member.Accounts {
+		if i, ok := s.accounts[account.Tag]; ok {
+			row[i] = account.RuneScapeName
+		}
+	}
+
+	return row
+}
+
+// cellString converts a sheet cell into a string without panicking on non-string cells.
+func cellString(cell interface{}) string {
+	if cell == nil {
+		return ""
+	}
+	if s, ok := cell.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(cell)
+}
+
+// isBlankRow returns whether every cell in the row is empty.
+func isBlankRow(row []interface{}) bool {
+	for _, cell := range row {
+		if len(strings.TrimSpace(cellString(cell))) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// layoutSheetRows returns the 1-based sheet rows members and unparsable rows are written to. Rows keep their order
+// relative to each other, closing the gaps left by removed members, and members without a row yet are written last.
+func layoutSheetRows(members []Member, issues []RowIssue) ([]int, []int) {
+	type entry struct {
+		row   int
+		issue bool
+		index int
+	}
+
+	entries := []entry{}
+	for i, v := range members {
+		entries = append(entries, entry{row: v.Row, index: i})
+	}
+	for i, v := range issues {
+		entries = append(entries, entry{row: v.Row, issue: true, index: i})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].row == 0 || entries[j].row == 0 {
+			return entries[j].row == 0 && entries[i].row != 0
+		}
+		return entries[i].row < entries[j].row
+	})
+
+	memberRows := make([]int, len(members))
+	issueRows := make([]int, len(issues))
+	for i, v := range entries {
+		// Row 1 of the sheet is the header.
+		if v.issue {
+			issueRows[v.index] = i + 2
+		} else {
+			memberRows[v.index] = i + 2
+		}
+	}
+
+	return memberRows, issueRows
+}
+
+// parseMemberlistValues parses the memberlist sheet, header row included, into members and a report of rejected rows.
+func parseMemberlistValues(values [][]interface{}) (*SheetSchema, []Member, []RowIssue, error) {
+	if len(values) == 0 {
+		return nil, nil, nil, errors.New("memberlist sheet is empty")
+	}
+
+	schema, err := NewSheetSchema(values[0])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	members := []Member{}
+	issues := []RowIssue{}
+	for i, v := range values[1:] {
+		// values[0] is the header, which lives in row 1 of the sheet.
+		row := i + 2
+		if isBlankRow(v) {
+			continue
+		}
+
+		member := Member{
+			Uuid:        schema.Get(v, ColumnUuid),
+			Name:        schema.Get(v, ColumnName),
+			DiscordID:   schema.Get(v, ColumnDiscordID),
+			TeamSpeakID: schema.Get(v, ColumnTeamSpeakID),
+			Accounts:    schema.readAccounts(v),
+			Rank:        schema.Get(v, ColumnRank),
+			Row:         row,
+		}
+
+		if len(member.Name) == 0 {
+			issues = append(issues, RowIssue{Row: row, Reason: "name is empty", Values: v})
+			continue
+		}
+
+		members = append(members, member)
+	}
+
+	return schema, members, issues, nil
+}
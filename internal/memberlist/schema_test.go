@@ -0,0 +1,119 @@
+package memberlist
+
+import (
+	"testing"
+)
+
+func TestParseMemberlistValues(t *testing.T) {
+	t.Parallel()
+
+	values := [][]interface{}{
+		{"Rank", "Name", "UUID", "Discord ID", "LPC", "XLPC", "Iron RSN", "Primary"},
+		{"Member", "joey", "1", "223169696055296011", "bender life", "bender xlpc", "bender iron", "IRON"},
+		{},
+		{"Veteran", "", "2"},
+		{"Applicant", "short row"},
+		{nil, 42.0},
+	}
+
+	schema, members, issues, err := parseMemberlistValues(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(members) != 3 {
+		t.Fatalf("Expected 3 members, got %d", len(members))
+	}
+	if members[0].GetAccount(AccountLPC) != "bender life" || members[0].GetAccount(AccountXLPC) != "bender xlpc" || members[0].GetAccount("iron") != "bender iron" {
+		t.Errorf("Expected accounts to be read by header, got %+v", members[0].Accounts)
+	}
+	if primary := members[0].Accounts.Primary(); primary == nil || primary.Tag != "iron" {
+		t.Errorf("Expected iron to be the primary account, got %+v", primary)
+	}
+	if primary := members[1].Accounts.Primary(); primary != nil {
+		t.Errorf("Expected a member without accounts to have no primary account, got %+v", primary)
+	}
+	if members[0].Rank != "Member" || members[0].Row != 2 {
+		t.Errorf("Expected rank Member on row 2, got %s on row %d", members[0].Rank, members[0].Row)
+	}
+	if members[1].Name != "short row" || members[1].Uuid != "" {
+		t.Errorf("Expected short row to parse with empty uuid, got %+v", members[1])
+	}
+	if members[2].Name != "42" {
+		t.Errorf("Expected non-string cell to be converted, got %s", members[2].Name)
+	}
+
+	if len(issues) != 1 || issues[0].Row != 4 {
+		t.Fatalf("Expected a single issue on row 4, got %+v", issues)
+	}
+
+	missing := schema.MissingColumns()
+	if len(missing) != 1 || missing[0] != ColumnTeamSpeakID {
+		t.Errorf("Expected teamspeak_id to be missing, got %v", missing)
+	}
+}
+
+func TestParseMemberlistValuesWithoutNameColumn(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, err := parseMemberlistValues([][]interface{}{{"UUID", "Rank"}})
+	if err != ErrMissingNameColumn {
+		t.Errorf("Expected ErrMissingNameColumn, got %v", err)
+	}
+}
+
+func TestSheetSchemaAddsAccountColumns(t *testing.T) {
+	t.Parallel()
+
+	schema, err := NewSheetSchema([]interface{}{"Name", "LPC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	member := Member{Name: "joey"}
+	member.SetAccount(AccountLPC, "bender life")
+	member.SetAccount("Main", "bender main")
+	member.SetPrimaryAccount("main")
+	if err := schema.ensureColumns(member); err != nil {
+		t.Fatal(err)
+	}
+
+	header := []string{}
+	for _, cell := range schema.Header() {
+		header = append(header, cellString(cell))
+	}
+	row := schema.Row(member)
+	if len(header) != 4 || header[2] != "MAIN RSN" || header[3] != "Primary Account" {
+		t.Fatalf("Expected main and primary account columns to be added, got %v", header)
+	}
+	if row[1] != "bender life" || row[2] != "bender main" || row[3] != "main" {
+		t.Errorf("Expected the row to hold every account, got %v", row)
+	}
+
+	_, members, _, err := parseMemberlistValues([][]interface{}{schema.Header(), row})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if members[0].GetAccount("main") != "bender main" || members[0].PrimaryAccountTag() != "main" {
+		t.Errorf("Expected the written row to parse back, got %+v", members[0].Accounts)
+	}
+}
+
+func TestLayoutSheetRowsKeepsUnparsableRowsInPlace(t *testing.T) {
+	t.Parallel()
+
+	members := []Member{
+		{Name: "Corgi", Row: 2},
+		{Name: "Pug", Row: 5},
+		{Name: "Recruit"},
+	}
+	issues := []RowIssue{{Row: 4, Reason: "name is empty"}}
+
+	memberRows, issueRows := layoutSheetRows(members, issues)
+	if memberRows[0] != 2 || memberRows[1] != 4 || memberRows[2] != 5 {
+		t.Errorf("Expected members in rows 2, 4 and 5, got %v", memberRows)
+	}
+	if issueRows[0] != 3 {
+		t.Errorf("Expected the unparsable row to stay between Corgi and Pug in row 3, got %v", issueRows)
+	}
+}
@@ -0,0 +1,146 @@
+package memberlist
+
+import (
+	"sort"
+	"strings"
+)
+
+// MINIMUM_MATCH_SCORE is the lowest score a search match needs to be returned.
+const MINIMUM_MATCH_SCORE = 0.6
+
+// Identity sources a member can be matched on.
+const (
+	IdentityName            = "name"
+	IdentityDiscordID       = "discord_id"
+	IdentityDiscordUsername = "discord_username"
+	IdentityDiscordNickname = "discord_nickname"
+	IdentityTeamSpeakID     = "teamspeak_id"
+)
+
+// Identity is one of the names or IDs a member is known by.
+type Identity struct {
+	// Source is where the identity comes from, e.g. name or lpc.
+	Source string
+	// Value is the identity itself.
+	Value string
+}
+
+// MemberMatch is a member matching a search, with the identity that matched best.
+type MemberMatch struct {
+	Member   Member
+	Identity Identity
+	// Score is how well the identity matched, from 0 to 1.
+	Score float64
+}
+
+// Identities returns every identity on the member's memberlist entry.
+func (m Member) Identities() []Identity {
+	identities := []Identity{
+		{Source: IdentityName, Value: m.Name},
+		{Source: IdentityDiscordID, Value: m.DiscordID},
+		{Source: IdentityTeamSpeakID, Value: m.TeamSpeakID},
+	}
+	for _, account := range m.Accounts {
+		identities = append(identities, Identity{Source: account.Tag, Value: account.RuneScapeName})
+	}
+
+	return identities
+}
+
+// normaliseIdentity lowercases a name and strips the separators RuneScape treats as equivalent.
+func normaliseIdentity(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '_' || r == '-' || r == '\u00a0' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(value)))
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(br)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// matchScore scores how well a normalised query matches a normalised value, from 0 to 1.
+func matchScore(query string, value string) float64 {
+	if len(query) == 0 || len(value) == 0 {
+		return 0
+	}
+
+	switch {
+	case query == value:
+		return 1
+	case strings.HasPrefix(value, query):
+		return 0.9
+	case strings.Contains(value, query):
+		return 0.75
+	}
+
+	longest := len([]rune(query))
+	if n := len([]rune(value)); n > longest {
+		longest = n
+	}
+
+	return 1 - float64(levenshtein(query, value))/float64(longest)
+}
+
+// SearchMembers fuzzy matches a query against every identity of the members, plus any extra identities such as
+// Discord usernames, and returns up to limit members ordered from the best match.
+func SearchMembers(members []Member, query string, extraIdentities func(Member) []Identity, limit int) []MemberMatch {
+	query = normaliseIdentity(query)
+	matches := []MemberMatch{}
+	for _, member := range members {
+		identities := member.Identities()
+		if extraIdentities != nil {
+			identities = append(identities, extraIdentities(member)...)
+		}
+
+		best := MemberMatch{Member: member}
+		for _, identity := range identities {
+			if score := matchScore(query, normaliseIdentity(identity.Value)); score > best.Score {
+				best.Score = score
+				best.Identity = identity
+			}
+		}
+
+		if best.Score >= MINIMUM_MATCH_SCORE {
+			matches = append(matches, best)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
@@ -0,0 +1,47 @@
+package memberlist
+
+import (
+	"testing"
+)
+
+func TestSearchMembers(t *testing.T) {
+	t.Parallel()
+
+	members := []Member{
+		{Name: "joey", DiscordID: "223169696055296011", Accounts: RuneScapeAccounts{{Tag: AccountLPC, RuneScapeName: "Bender Life"}}},
+		{Name: "lord ex", TeamSpeakID: "lordex=", Accounts: RuneScapeAccounts{{Tag: AccountXLPC, RuneScapeName: "i ex i"}}},
+	}
+	extra := func(member Member) []Identity {
+		if member.Name == "joey" {
+			return []Identity{{Source: IdentityDiscordUsername, Value: "joeydotdev"}}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		query          string
+		expectedName   string
+		expectedSource string
+	}{
+		{"bender_life", "joey", AccountLPC},
+		{"benderlfe", "joey", AccountLPC},
+		{"joeydot", "joey", IdentityDiscordUsername},
+		{"I-Ex-I", "lord ex", AccountXLPC},
+		{"223169696055296011", "joey", IdentityDiscordID},
+	}
+
+	for _, test := range tests {
+		matches := SearchMembers(members, test.query, extra, 1)
+		if len(matches) != 1 {
+			t.Errorf("Expected a match for %q, got none", test.query)
+			continue
+		}
+		if matches[0].Member.Name != test.expectedName || matches[0].Identity.Source != test.expectedSource {
+			t.Errorf("Expected %q to match %s on %s, got %s on %s", test.query, test.expectedName, test.expectedSource, matches[0].Member.Name, matches[0].Identity.Source)
+		}
+	}
+
+	if matches := SearchMembers(members, "zezima", extra, 0); len(matches) != 0 {
+		t.Errorf("Expected no matches for zezima, got %+v", matches)
+	}
+}
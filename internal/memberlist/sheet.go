@@ -3,6 +3,8 @@ package memberlist
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"os"
 
 	"golang.org/x/oauth2/google"
@@ -12,11 +14,18 @@ import (
 
 // https://docs.google.com/spreadsheets/d/<SPREADSHEETID>/edit#gid=<SHEETID>
 const (
-	MEMBERLIST_SPREADSHEET_ID   = "10vC_oi6rgBmVqJKgymokWobIvXOiP8yLx9F4sgfT994"
-	MEMBERLIST_SHEED_ID         = "0"
-	MEMBERLIST_SHEET_READ_RANGE = "A2:G200"
+	MEMBERLIST_SPREADSHEET_ID     = "10vC_oi6rgBmVqJKgymokWobIvXOiP8yLx9F4sgfT994"
+	MEMBERLIST_SHEED_ID           = "0"
+	MEMBERLIST_SHEET_READ_RANGE   = "A1:Z"
+	MEMBERLIST_SHEET_HEADER_RANGE = "A1:Z1"
+	MEMBERLIST_SHEET_WRITE_RANGE  = "A2:Z"
 )
 
+// MEMBERLIST_SHEET_URL is the address of the memberlist sheet in the browser.
+var MEMBERLIST_SHEET_URL = fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/edit#gid=%s", MEMBERLIST_SPREADSHEET_ID, MEMBERLIST_SHEED_ID)
+
+var ErrMemberlistNotLoaded error = errors.New("memberlist has not been loaded from the sheet; refusing to overwrite it")
+
 var sheetInstance *sheets.Service
 
 func init() {
@@ -37,6 +46,11 @@ func init() {
 	}
 }
 
+// SheetRowURL returns a link selecting a row of the memberlist sheet.
+func SheetRowURL(row int) string {
+	return fmt.Sprintf("%s&range=%d:%d", MEMBERLIST_SHEET_URL, row, row)
+}
+
 func GetMemberlistSheet() (*sheets.ValueRange, error) {
 	resp, err := sheetInstance.Spreadsheets.Values.Get(MEMBERLIST_SPREADSHEET_ID, MEMBERLIST_SHEET_READ_RANGE).Do()
 	if err != nil {
@@ -46,22 +60,52 @@ func GetMemberlistSheet() (*sheets.ValueRange, error) {
 	return resp, nil
 }
 
+// UpdateMemberlistSheet writes the memberlist back to the sheet in the column order of its header.
+// Rows that could not be parsed are written back untouched in their original position; see layoutSheetRows.
 func UpdateMemberlistSheet(m *Memberlist) error {
-	var sheetValues [][]interface{}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return writeMemberlistSheet(m)
+}
+
+// writeMemberlistSheet writes the memberlist to the sheet. The caller must hold the memberlist lock.
+func writeMemberlistSheet(m *Memberlist) error {
+	if m.schema == nil {
+		return ErrMemberlistNotLoaded
+	}
+
 	for _, v := range m.Members {
-		sheetValues = append(sheetValues,
-			[]interface{}{
-				v.Uuid,
-				v.Name,
-				v.DiscordID,
-				v.TeamSpeakID,
-				v.Accounts.XLPC,
-				v.Accounts.LPC,
-				v.Rank,
-			})
+		if err := m.schema.ensureColumns(v); err != nil {
+			return err
+		}
 	}
 
-	_, err := sheetInstance.Spreadsheets.Values.Update(MEMBERLIST_SPREADSHEET_ID, MEMBERLIST_SHEET_READ_RANGE, &sheets.ValueRange{
+	if m.schema.headerChanged {
+		_, err := sheetInstance.Spreadsheets.Values.Update(MEMBERLIST_SPREADSHEET_ID, MEMBERLIST_SHEET_HEADER_RANGE, &sheets.ValueRange{
+			Values: [][]interface{}{m.schema.Header()},
+		}).ValueInputOption("USER_ENTERED").Do()
+		if err != nil {
+			return err
+		}
+		m.schema.headerChanged = false
+	}
+
+	memberRows, issueRows := layoutSheetRows(m.Members, m.Issues)
+	sheetValues := make([][]interface{}, len(m.Members)+len(m.Issues))
+	for i, v := range m.Members {
+		sheetValues[memberRows[i]-2] = m.schema.Row(v)
+	}
+	for i, v := range m.Issues {
+		sheetValues[issueRows[i]-2] = v.Values
+	}
+
+	// Blank out rows left over from a longer memberlist.
+	for len(sheetValues)+1 < m.rowCount {
+		sheetValues = append(sheetValues, m.schema.Row(Member{}))
+	}
+
+	_, err := sheetInstance.Spreadsheets.Values.Update(MEMBERLIST_SPREADSHEET_ID, MEMBERLIST_SHEET_WRITE_RANGE, &sheets.ValueRange{
 		Values: sheetValues,
 	}).ValueInputOption("USER_ENTERED").Do()
 
@@ -69,5 +113,13 @@ func UpdateMemberlistSheet(m *Memberlist) error {
 		return err
 	}
 
+	for i := range m.Members {
+		m.Members[i].Row = memberRows[i]
+	}
+	for i := range m.Issues {
+		m.Issues[i].Row = issueRows[i]
+	}
+	m.rowCount = len(sheetValues) + 1
+	m.version++
 	return nil
 }
@@ -0,0 +1,282 @@
+package memberlist
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Formats the memberlist can be exported to and imported from.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var ErrMemberlistChanged error = errors.New("memberlist has changed since the replacement was prepared")
+var ErrUnknownFormat error = errors.New("unknown format; valid formats are csv and json")
+
+// memberlistDocument is the JSON representation of an exported memberlist.
+type memberlistDocument struct {
+	Members []Member `json:"members"`
+}
+
+// Export serialises the memberlist into the given format.
+func (m *Memberlist) Export(format string) ([]byte, error) {
+	members := m.GetMembers()
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(memberlistDocument{Members: members}, "", "  ")
+	case FormatCSV:
+		schema := defaultSheetSchema(members)
+		buf := &bytes.Buffer{}
+		writer := csv.NewWriter(buf)
+		header := []string{}
+		for _, cell := range schema.Header() {
+			header = append(header, cellString(cell))
+		}
+		if err := writer.Write(header); err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			row := schema.Row(member)
+			record := make([]string, len(row))
+			for i, cell := range row {
+				record[i] = cellString(cell)
+			}
+			if err := writer.Write(record); err != nil {
+				return nil, err
+			}
+		}
+
+		writer.Flush()
+		return buf.Bytes(), writer.Error()
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// ParseImport parses an exported memberlist. CSV files are read by their header, like the memberlist sheet.
+func ParseImport(format string, data []byte) ([]Member, []RowIssue, error) {
+	switch format {
+	case FormatJSON:
+		document := memberlistDocument{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, nil, err
+		}
+		return document.Members, []RowIssue{}, nil
+	case FormatCSV:
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		values := make([][]interface{}, len(records))
+		for i, record := range records {
+			values[i] = make([]interface{}, len(record))
+			for j, cell := range record {
+				values[i][j] = cell
+			}
+		}
+
+		_, members, issues, err := parseMemberlistValues(values)
+		return members, issues, err
+	}
+
+	return nil, nil, ErrUnknownFormat
+}
+
+// ValidateMembers checks a list of members against the memberlist schema and returns a description of every problem found.
+func ValidateMembers(members []Member) []string {
+	problems := []string{}
+	uuids := make(map[string]string)
+	discordIDs := make(map[string]string)
+	for _, member := range members {
+		if len(strings.TrimSpace(member.Name)) == 0 {
+			problems = append(problems, fmt.Sprintf("member with UUID %q has no name", member.Uuid))
+			continue
+		}
+		if len(member.Rank) > 0 && GetRankByName(member.Rank) == nil {
+			problems = append(problems, fmt.Sprintf("%s has unknown rank %q", member.Name, member.Rank))
+		}
+		if len(member.Uuid) > 0 {
+			if other, ok := uuids[member.Uuid]; ok {
+				problems = append(problems, fmt.Sprintf("%s and %s share UUID %s", other, member.Name, member.Uuid))
+			}
+			uuids[member.Uuid] = member.Name
+		}
+		if len(member.DiscordID) > 0 {
+			if other, ok := discordIDs[member.DiscordID]; ok {
+				problems = append(problems, fmt.Sprintf("%s and %s share Discord ID %s", other, member.Name, member.DiscordID))
+			}
+			discordIDs[member.DiscordID] = member.Name
+		}
+
+		tags := make(map[string]bool)
+		for _, account := range member.Accounts {
+			tag, err := NormaliseAccountTag(account.Tag)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s has invalid account tag %q: %s", member.Name, account.Tag, err))
+				continue
+			}
+			if tags[tag] {
+				problems = append(problems, fmt.Sprintf("%s has more than one %s account", member.Name, tag))
+			}
+			tags[tag] = true
+		}
+	}
+
+	return problems
+}
+
+// MemberChange is a member present in both lists whose fields differ.
+type MemberChange struct {
+	Before Member
+	After  Member
+	// Fields are the names of the changed fields.
+	Fields []string
+}
+
+// MemberlistDiff is the difference between the current memberlist and an imported one.
+type MemberlistDiff struct {
+	Added   []Member
+	Removed []Member
+	Changed []MemberChange
+}
+
+// IsEmpty returns whether the diff has no changes.
+func (d MemberlistDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// isSameMember returns whether two entries describe the same member, comparing UUIDs, then Discord IDs, then names.
+func isSameMember(a Member, b Member) bool {
+	if len(a.Uuid) > 0 && len(b.Uuid) > 0 {
+		return a.Uuid == b.Uuid
+	}
+	if len(a.DiscordID) > 0 && len(b.DiscordID) > 0 {
+		return a.DiscordID == b.DiscordID
+	}
+
+	return strings.EqualFold(a.Name, b.Name)
+}
+
+// accountTagsOf returns the tags of every account of the given members, in order of first appearance.
+func accountTagsOf(members ...Member) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, member := range members {
+		for _, tag := range member.Accounts.Tags() {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// fieldChange is the value of a member field before and after a change.
+type fieldChange struct {
+	name   string
+	before string
+	after  string
+}
+
+func changedFields(before Member, after Member) []string {
+	changes := []fieldChange{
+		{"name", before.Name, after.Name},
+		{"rank", before.Rank, after.Rank},
+		{"discord_id", before.DiscordID, after.DiscordID},
+		{"teamspeak_id", before.TeamSpeakID, after.TeamSpeakID},
+	}
+	for _, tag := range accountTagsOf(before, after) {
+		changes = append(changes, fieldChange{tag, before.GetAccount(tag), after.GetAccount(tag)})
+	}
+	changes = append(changes, fieldChange{ColumnPrimaryAccount, before.PrimaryAccountTag(), after.PrimaryAccountTag()})
+
+	fields := []string{}
+	for _, change := range changes {
+		if change.before != change.after {
+			fields = append(fields, change.name)
+		}
+	}
+
+	return fields
+}
+
+// ReconcileImport matches imported members against the current memberlist. Imported members keep the UUID of the
+// member they match, and new members without a UUID are given one. The reconciled members are returned with the diff.
+func ReconcileImport(current []Member, incoming []Member) ([]Member, MemberlistDiff) {
+	diff := MemberlistDiff{}
+	reconciled := make([]Member, len(incoming))
+	matched := make([]bool, len(current))
+	for i, member := range incoming {
+		found := -1
+		for j, v := range current {
+			if !matched[j] && isSameMember(v, member) {
+				found = j
+				break
+			}
+		}
+
+		if found < 0 {
+			if len(member.Uuid) == 0 {
+				member.Uuid = uuid.New().String()
+			}
+			diff.Added = append(diff.Added, member)
+			reconciled[i] = member
+			continue
+		}
+
+		matched[found] = true
+		before := current[found]
+		if len(before.Uuid) > 0 {
+			member.Uuid = before.Uuid
+		} else if len(member.Uuid) == 0 {
+			member.Uuid = uuid.New().String()
+		}
+		member.Row = before.Row
+		if fields := changedFields(before, member); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, MemberChange{Before: before, After: member, Fields: fields})
+		}
+		reconciled[i] = member
+	}
+
+	for j, v := range current {
+		if !matched[j] {
+			diff.Removed = append(diff.Removed, v)
+		}
+	}
+
+	return reconciled, diff
+}
+
+// ReplaceMembers replaces every member of the memberlist and writes the result to the sheet, provided the memberlist is
+// still at the given version; see Version. ErrMemberlistChanged is returned otherwise, so that changes made since the
+// replacement was prepared are not silently overwritten. If the sheet cannot be written the previous members are restored.
+func (m *Memberlist) ReplaceMembers(members []Member, version int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.version != version {
+		return ErrMemberlistChanged
+	}
+
+	previous := m.Members
+	m.Members = members
+	if err := writeMemberlistSheet(m); err != nil {
+		m.Members = previous
+		return err
+	}
+
+	return nil
+}
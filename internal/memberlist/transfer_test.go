@@ -0,0 +1,93 @@
+package memberlist
+
+import (
+	"testing"
+)
+
+func TestExportAndReconcileImport(t *testing.T) {
+	t.Parallel()
+
+	current := []Member{
+		{Uuid: "1", Name: "joey", DiscordID: "10", Rank: "Member", Accounts: RuneScapeAccounts{{Tag: AccountLPC, RuneScapeName: "bender life"}}},
+		{Uuid: "2", Name: "lord ex", DiscordID: "20", Rank: "Veteran"},
+	}
+
+	for _, format := range []string{FormatCSV, FormatJSON} {
+		data, err := (&Memberlist{Members: current}).Export(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		imported, issues, err := ParseImport(format, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 0 || len(imported) != len(current) {
+			t.Fatalf("Expected %s export to round trip, got %+v and issues %+v", format, imported, issues)
+		}
+		if _, diff := ReconcileImport(current, imported); !diff.IsEmpty() {
+			t.Errorf("Expected %s round trip to have no changes, got %+v", format, diff)
+		}
+	}
+
+	incoming := []Member{
+		{Name: "joey", DiscordID: "10", Rank: "Veteran", Accounts: RuneScapeAccounts{{Tag: AccountLPC, RuneScapeName: "bender life"}}},
+		{Name: "new member", Rank: "Applicant"},
+	}
+	reconciled, diff := ReconcileImport(current, incoming)
+	if len(diff.Added) != 1 || len(diff.Removed) != 1 || len(diff.Changed) != 1 {
+		t.Fatalf("Expected 1 added, 1 removed and 1 changed member, got %+v", diff)
+	}
+	if reconciled[0].Uuid != "1" || len(reconciled[1].Uuid) == 0 {
+		t.Errorf("Expected UUIDs to be carried over and assigned, got %+v", reconciled)
+	}
+	if fields := diff.Changed[0].Fields; len(fields) != 1 || fields[0] != "rank" {
+		t.Errorf("Expected only rank to change, got %v", fields)
+	}
+}
+
+func TestValidateMembers(t *testing.T) {
+	t.Parallel()
+
+	problems := ValidateMembers([]Member{
+		{Uuid: "1", Name: "joey", DiscordID: "10", Rank: "Member"},
+		{Uuid: "1", Name: "lord ex", DiscordID: "10", Rank: "General"},
+		{Uuid: "3"},
+	})
+	if len(problems) != 4 {
+		t.Errorf("Expected 4 problems, got %v", problems)
+	}
+}
+
+func TestValidateMembersChecksAccountTags(t *testing.T) {
+	t.Parallel()
+
+	members, _, err := ParseImport(FormatJSON, []byte(`{"members": [
+		{"name": "joey", "runescape_accounts": [{"tag": " LPC ", "runescape_name": "bender life"}]},
+		{"name": "lord ex", "runescape_accounts": [{"tag": "Foo Bar", "runescape_name": "lord ex"}]},
+		{"name": "duo", "runescape_accounts": [{"tag": "main", "runescape_name": "duo"}, {"tag": "MAIN", "runescape_name": "duo two"}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag := members[0].Accounts[0].Tag; tag != AccountLPC {
+		t.Errorf("Expected tag to be normalised to %q, got %q", AccountLPC, tag)
+	}
+
+	problems := ValidateMembers(members)
+	if len(problems) != 2 {
+		t.Errorf("Expected an invalid and a duplicate tag to be reported, got %v", problems)
+	}
+}
+
+func TestReplaceMembersRejectsStaleVersion(t *testing.T) {
+	t.Parallel()
+
+	m := &Memberlist{Members: []Member{{Uuid: "1", Name: "joey"}}, version: 2}
+	if err := m.ReplaceMembers([]Member{{Uuid: "2", Name: "lord ex"}}, 1); err != ErrMemberlistChanged {
+		t.Fatalf("Expected ErrMemberlistChanged, got %v", err)
+	}
+	if members := m.GetMembers(); len(members) != 1 || members[0].Name != "joey" {
+		t.Errorf("Expected the memberlist to be left alone, got %+v", members)
+	}
+}
@@ -0,0 +1,268 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/applications"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/rsnwatch"
+)
+
+const (
+	ApplicationPluginName         = "ApplicationPlugin"
+	ApplicationCustomIDPrefix     = "application"
+	APPLICATION_COLOR             = 0x60a5fa
+	APPLICATION_NAME_INPUT        = "name"
+	APPLICATION_TEAMSPEAK_INPUT   = "teamspeak"
+	APPLICATION_ABOUT_INPUT       = "about"
+	MAXIMUM_APPLICATION_ABOUT_LEN = 1000
+)
+
+var AlreadyInMemberlistError error = errors.New("You are already on the memberlist.")
+var MissingApplicationNameError error = errors.New("Your application needs a name.")
+var NoApplicantRankError error = errors.New("The Applicant rank does not exist.")
+
+type ApplicationPlugin struct{}
+
+// Enabled returns whether or not the ApplicationPlugin is enabled.
+func (a *ApplicationPlugin) Enabled() bool {
+	return true
+}
+
+// NewApplicationPlugin creates a new ApplicationPlugin.
+func NewApplicationPlugin() *ApplicationPlugin {
+	return &ApplicationPlugin{}
+}
+
+// Name returns the name of the plugin.
+func (a *ApplicationPlugin) Name() string {
+	return ApplicationPluginName
+}
+
+// Validate validates whether or not we should execute ApplicationPlugin on an incoming Discord message.
+func (a *ApplicationPlugin) Validate(session *discordgo.Session, message *discordgo.MessageCreate) bool {
+	return strings.Split(message.Content, " ")[0] == "!apply"
+}
+
+// Execute replies with a button that opens the application form.
+func (a *ApplicationPlugin) Execute(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if getMemberlist().GetMemberByDiscordID(message.Author.ID) != nil {
+		return AlreadyInMemberlistError
+	}
+
+	_, err := session.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+		Content:   "Press the button below to apply to join the clan.",
+		Reference: message.Reference(),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Apply",
+						Style:    discordgo.PrimaryButton,
+						CustomID: buildCustomID(ApplicationCustomIDPrefix, "open"),
+					},
+				},
+			},
+		},
+	})
+	return err
+}
+
+// ValidateInteraction validates whether or not we should execute ApplicationPlugin on an incoming Discord interaction.
+func (a *ApplicationPlugin) ValidateInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) bool {
+	return isInteractionFor(interaction, ApplicationCustomIDPrefix)
+}
+
+// ExecuteInteraction opens the application form, handles its submission and handles officer reviews.
+func (a *ApplicationPlugin) ExecuteInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	_, action, args := parseCustomID(interactionCustomID(interaction))
+
+	switch action {
+	case "open":
+		return a.openForm(session, interaction)
+	case "submit":
+		return a.submit(session, interaction)
+	case "accept", "reject":
+		if len(args) < 1 {
+			return TooFewArgumentsError
+		}
+		if err := requireOfficerInteraction(interaction); err != nil {
+			return err
+		}
+		return a.review(action, args[0], session, interaction)
+	}
+
+	return InvalidOperationError
+}
+
+// textInputRow wraps a single text input in an actions row, as modals require.
+func textInputRow(customID string, label string, style discordgo.TextInputStyle, required bool, maxLength int) discordgo.ActionsRow {
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.TextInput{
+				CustomID:  customID,
+				Label:     label,
+				Style:     style,
+				Required:  required,
+				MaxLength: maxLength,
+			},
+		},
+	}
+}
+
+// openForm shows the applicant a modal collecting their RSNs and details.
+func (a *ApplicationPlugin) openForm(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	if getMemberlist().GetMemberByDiscordID(interactionUser(interaction).ID) != nil {
+		return AlreadyInMemberlistError
+	}
+
+	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: buildCustomID(ApplicationCustomIDPrefix, "submit"),
+			Title:    "Clan application",
+			Components: []discordgo.MessageComponent{
+				textInputRow(APPLICATION_NAME_INPUT, "Name", discordgo.TextInputShort, true, 32),
+				textInputRow(memberlistentity.AccountLPC, "LPC RSN", discordgo.TextInputShort, false, rsnwatch.MaximumRuneScapeNameLength),
+				textInputRow(memberlistentity.AccountXLPC, "XLPC RSN", discordgo.TextInputShort, false, rsnwatch.MaximumRuneScapeNameLength),
+				textInputRow(APPLICATION_TEAMSPEAK_INPUT, "TeamSpeak ID", discordgo.TextInputShort, false, 64),
+				textInputRow(APPLICATION_ABOUT_INPUT, "Tell us about yourself", discordgo.TextInputParagraph, false, MAXIMUM_APPLICATION_ABOUT_LEN),
+			},
+		},
+	})
+}
+
+// submit verifies a submitted application and posts it for officer review.
+func (a *ApplicationPlugin) submit(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	values := modalValues(interaction)
+	if len(values[APPLICATION_NAME_INPUT]) == 0 {
+		return MissingApplicationNameError
+	}
+
+	// Hiscores lookups can take longer than Discord allows for a response, so acknowledge the submission first.
+	err := session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	user := interactionUser(interaction)
+	member := memberlistentity.Member{}
+	for _, account := range memberlistentity.ACCOUNTS {
+		if err := member.SetAccount(account, values[account]); err != nil {
+			return err
+		}
+	}
+
+	application := applications.NewApplication(user.ID, user.String(), values[APPLICATION_NAME_INPUT], member.Accounts, values[APPLICATION_TEAMSPEAK_INPUT], values[APPLICATION_ABOUT_INPUT])
+	application.Verify(lookupPlayer)
+	if err := application.Save(); err != nil {
+		return err
+	}
+
+	_, err = session.ChannelMessageSendComplex(discord.ApplicationReviewChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("New application from <@%s>.", user.ID),
+		Embeds:  []*discordgo.MessageEmbed{buildApplicationEmbed(application)},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Accept",
+						Style:    discordgo.SuccessButton,
+						CustomID: buildCustomID(ApplicationCustomIDPrefix, "accept", application.Uuid),
+					},
+					discordgo.Button{
+						Label:    "Reject",
+						Style:    discordgo.DangerButton,
+						CustomID: buildCustomID(ApplicationCustomIDPrefix, "reject", application.Uuid),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	content := "Thanks! Your application has been sent to the officers."
+	_, err = session.InteractionResponseEdit(interaction.Interaction, &discordgo.WebhookEdit{Content: &content})
+	return err
+}
+
+// buildApplicationEmbed summarises an application and the problems found verifying it.
+func buildApplicationEmbed(application *applications.Application) *discordgo.MessageEmbed {
+	member := application.ToMember()
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Discord", Value: application.DiscordName, Inline: true},
+		{Name: "TeamSpeak ID", Value: orNone(application.TeamSpeakID), Inline: true},
+	}
+	for _, account := range memberlistentity.ACCOUNTS {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: strings.ToUpper(account), Value: orNone(member.GetAccount(account)), Inline: true})
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{Name: "About", Value: orNone(application.About)})
+
+	checks := "All checks passed."
+	if len(application.Problems) > 0 {
+		checks = "⚠️ " + strings.Join(application.Problems, "\n⚠️ ")
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{Name: "Checks", Value: checks})
+
+	return &discordgo.MessageEmbed{
+		Title:  application.Name,
+		Color:  APPLICATION_COLOR,
+		Fields: fields,
+	}
+}
+
+// review accepts or rejects an application. Accepted applicants are added to the memberlist and given the Applicant role.
+func (a *ApplicationPlugin) review(action string, applicationID string, session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	application, err := applications.GetApplicationByUUID(applicationID)
+	if err != nil {
+		return err
+	}
+
+	user := interactionUser(interaction)
+	if action == "reject" {
+		if err := application.Review(applications.StatusRejected, user.String()); err != nil {
+			return err
+		}
+		if err := application.Save(); err != nil {
+			return err
+		}
+		notifyUser(session, application.DiscordID, "Your clan application was not accepted this time.")
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("❌ Rejected by %s.", user.String()))
+	}
+
+	rank := memberlistentity.GetRankByName(applications.ApplicantRankName)
+	if rank == nil {
+		return NoApplicantRankError
+	}
+
+	if err := application.Review(applications.StatusAccepted, user.String()); err != nil {
+		return err
+	}
+	if err := getMemberlist().AddMember(application.ToMember()); err != nil {
+		return err
+	}
+	if err := application.Save(); err != nil {
+		log.Printf("Failed to save accepted application %s: %v", application.Uuid, err)
+	}
+
+	note := fmt.Sprintf("✅ Accepted by %s.", user.String())
+	if err := session.GuildMemberRoleAdd(discord.GuildID, application.DiscordID, rank.RoleID); err != nil {
+		log.Printf("Failed to give %s the Applicant role: %v", application.DiscordID, err)
+		note += " Could not assign the Applicant role, please add it by hand."
+	}
+	notifyUser(session, application.DiscordID, "Your clan application has been accepted. Welcome!")
+
+	return resolveComponentMessage(session, interaction, note)
+}
@@ -3,8 +3,11 @@ package plugins
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/activity"
+	"github.com/joeydotdev/corgi-discord-bot/internal/attendance"
 	teamspeakentity "github.com/joeydotdev/corgi-discord-bot/internal/teamspeak"
 )
 
@@ -67,8 +70,26 @@ func (a *AttendanceCommandPlugin) Execute(session *discordgo.Session, message *d
 		return err
 	}
 
+	members := getMemberlist().GetMembers()
+	attendees := []attendance.Attendee{}
 	for _, client := range clients {
 		messageString += fmt.Sprintf("%s\n", client.Nickname)
+
+		attendee := attendance.Attendee{Nickname: client.Nickname, DatabaseID: client.DatabaseID}
+		if member := attendance.MatchAttendee(members, client.Nickname, client.DatabaseID); member != nil {
+			attendee.MemberKey = member.Key()
+		}
+		attendees = append(attendees, attendee)
+	}
+
+	snapshot, err := attendance.NewAttendanceSnapshot(attendanceSnapshotName, attendees)
+	if err != nil {
+		return err
+	}
+	for _, attendee := range snapshot.Attendees {
+		if len(attendee.MemberKey) > 0 {
+			recordActivity(getMemberlist().GetMemberByKey(attendee.MemberKey), activity.KindAttendance, time.Now())
+		}
 	}
 
 	_, err = session.ChannelMessageSend(message.ChannelID, messageString)
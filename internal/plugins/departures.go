@@ -0,0 +1,198 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/rankhistory"
+)
+
+const (
+	DeparturePluginName     = "DeparturePlugin"
+	DepartureCustomIDPrefix = "departure"
+)
+
+// clanRanks caches the clan rank of each guild member by Discord ID.
+// Discord does not include roles when a member leaves, and discordgo drops the member from its state before handlers run.
+var clanRanks = make(map[string]memberlistentity.Rank)
+var clanRanksMutex sync.Mutex
+
+type DeparturePlugin struct{}
+
+// Enabled returns whether or not the DeparturePlugin is enabled.
+func (d *DeparturePlugin) Enabled() bool {
+	return true
+}
+
+// NewDeparturePlugin creates a new DeparturePlugin.
+func NewDeparturePlugin() *DeparturePlugin {
+	return &DeparturePlugin{}
+}
+
+// Name returns the name of the plugin.
+func (d *DeparturePlugin) Name() string {
+	return DeparturePluginName
+}
+
+// TrackClanRank records the clan rank of a guild member so it is known if they leave.
+func TrackClanRank(guildMember *discordgo.Member) {
+	if guildMember == nil || guildMember.User == nil {
+		return
+	}
+
+	clanRanksMutex.Lock()
+	defer clanRanksMutex.Unlock()
+
+	rank, _ := memberlistentity.GetDiscordMemberClanRank(guildMember)
+	if rank == nil {
+		delete(clanRanks, guildMember.User.ID)
+		return
+	}
+	clanRanks[guildMember.User.ID] = *rank
+}
+
+// takeClanRank returns and forgets the cached clan rank of a guild member.
+func takeClanRank(discordID string) *memberlistentity.Rank {
+	clanRanksMutex.Lock()
+	defer clanRanksMutex.Unlock()
+
+	rank, ok := clanRanks[discordID]
+	if !ok {
+		return nil
+	}
+	delete(clanRanks, discordID)
+
+	return &rank
+}
+
+// CacheClanRanks records the clan rank of every guild member.
+func CacheClanRanks(session *discordgo.Session) error {
+	guildMembers, err := getGuildMembers(session)
+	if err != nil {
+		return err
+	}
+
+	for _, guildMember := range guildMembers {
+		TrackClanRank(guildMember)
+	}
+
+	return nil
+}
+
+// HandleDeparture notifies the admin channel when a clan member or memberlist entry leaves the guild.
+func HandleDeparture(session *discordgo.Session, user *discordgo.User) error {
+	rank := takeClanRank(user.ID)
+	member := getMemberlist().GetMemberByDiscordID(user.ID)
+	if rank == nil && member == nil {
+		return nil
+	}
+
+	content := fmt.Sprintf("**%s** (<@%s>) left the Discord server.", user.String(), user.ID)
+	if rank != nil {
+		content += fmt.Sprintf(" They had the %s role.", rank.Name)
+	}
+	if member == nil {
+		_, err := session.ChannelMessageSend(discord.AdminNotificationsChannelID, content+" They are not on the memberlist.")
+		return err
+	}
+
+	record := fmt.Sprintf("\nMemberlist: **%s** · %s · TeamSpeak %s", member.Name, orNone(member.Rank), orNone(member.TeamSpeakID))
+	for _, account := range member.Accounts {
+		record += fmt.Sprintf(" · %s %s", account.Tag, account.RuneScapeName)
+	}
+
+	_, err := session.ChannelMessageSendComplex(discord.AdminNotificationsChannelID, &discordgo.MessageSend{
+		Content: content + record,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Mark departed",
+						Style:    discordgo.PrimaryButton,
+						CustomID: buildCustomID(DepartureCustomIDPrefix, "depart", member.Key()),
+					},
+					discordgo.Button{
+						Label:    "Remove from memberlist",
+						Style:    discordgo.DangerButton,
+						CustomID: buildCustomID(DepartureCustomIDPrefix, "remove", member.Key()),
+					},
+					discordgo.Button{
+						Label:    "Dismiss",
+						Style:    discordgo.SecondaryButton,
+						CustomID: buildCustomID(DepartureCustomIDPrefix, "dismiss"),
+					},
+				},
+			},
+		},
+	})
+	return err
+}
+
+// ValidateInteraction validates whether or not we should execute DeparturePlugin on an incoming Discord interaction.
+func (d *DeparturePlugin) ValidateInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) bool {
+	return isInteractionFor(interaction, DepartureCustomIDPrefix)
+}
+
+// ExecuteInteraction marks a departed member as such, removes them from the memberlist, or dismisses the notification.
+func (d *DeparturePlugin) ExecuteInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	if err := requireOfficerInteraction(interaction); err != nil {
+		return err
+	}
+
+	_, action, args := parseCustomID(interactionCustomID(interaction))
+	user := interactionUser(interaction)
+	if action == "dismiss" {
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("Dismissed by %s.", user.String()))
+	}
+
+	if len(args) < 1 {
+		return TooFewArgumentsError
+	}
+	member := getMemberlist().GetMemberByKey(args[0])
+	if member == nil {
+		return MemberNotFoundError
+	}
+
+	switch action {
+	case "depart":
+		// Only the rank is changed, so edits made since the notification was posted are kept.
+		var fromRank string
+		var updatedMember memberlistentity.Member
+		err := getMemberlist().ModifyMember(member.Key(), func(current memberlistentity.Member) (memberlistentity.Member, error) {
+			fromRank = current.Rank
+			current.Rank = memberlistentity.DepartedRankName
+			updatedMember = current
+			return current, nil
+		})
+		if errors.Is(err, memberlistentity.ErrMemberNotInMemberlist) {
+			return MemberNotFoundError
+		}
+		if err != nil {
+			return err
+		}
+
+		err = rankhistory.RecordRankChange(updatedMember, rankhistory.RankChange{
+			FromRank:    fromRank,
+			ToRank:      updatedMember.Rank,
+			ChangedByID: user.ID,
+			ChangedBy:   user.String(),
+			Reason:      "Left the Discord server",
+		})
+		if err != nil {
+			log.Printf("Failed to record departure of %s: %v", member.Name, err)
+		}
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("Marked as departed by %s.", user.String()))
+	case "remove":
+		if err := getMemberlist().RemoveMember(*member); err != nil {
+			return err
+		}
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("🗑️ Removed from the memberlist by %s.", user.String()))
+	}
+
+	return InvalidOperationError
+}
@@ -5,5 +5,10 @@ import "errors"
 var TooFewArgumentsError error = errors.New("Too few arguments")
 var InvalidOperationError error = errors.New("Invalid operation. Valid operations are: add, remove, update")
 var NoDiscordUsernameAndDiscriminatorError error = errors.New("No Discord username and discriminator provided.")
-var ActiveOngoingEventError error = errors.New("An event is already active. Please stop the current event before starting a new one.")
+var ActiveOngoingEventError error = errors.New("An event with that name is already active. Please stop it or choose another name.")
 var NoEventError error = errors.New("No event is currently active. Please start an event before trying to stop it.")
+var InvalidMemberlistOperationError error = errors.New("Invalid operation. Valid operations are: add, remove, update, lint, audit, history, validate, export, import, check")
+var AdminChannelOnlyError error = errors.New("This command can only be used in the admin notifications channel.")
+var InvalidXpTrackerOperationError error = errors.New("Invalid operation. Valid operations are: start, stop, status, retry, leaderboard, list, schedule, unschedule, history, chart")
+var EventNotFoundError error = errors.New("No event has that UUID or short name.")
+var InvalidEventNameError error = errors.New("Event names must contain at least one letter or digit.")
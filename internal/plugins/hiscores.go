@@ -0,0 +1,16 @@
+package plugins
+
+import (
+	"time"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+)
+
+// HISCORES_CACHE_TTL is how long hiscores lookups made by commands are remembered.
+const HISCORES_CACHE_TTL = 5 * time.Minute
+
+// hiscoresClient looks players up for commands. XP tracker snapshots bypass it, as they must be fresh.
+var hiscoresClient *hiscores.CachingClient = hiscores.NewCachingClient(hiscores.DefaultClient, HISCORES_CACHE_TTL)
+
+// lookupPlayer looks a player up on the regular hiscores through the cache.
+var lookupPlayer hiscores.Lookup = hiscores.LookupWith(hiscoresClient, hiscores.AccountTypeNormal)
@@ -0,0 +1,195 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/activity"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+const (
+	InactiveCommandPluginName = "InactiveCommandPlugin"
+	// DEFAULT_INACTIVITY_DAYS is the inactivity threshold used when `!inactive` is given no days.
+	DEFAULT_INACTIVITY_DAYS = 30
+	// ActivityFlushInterval is how often recorded activity is saved to the data store.
+	ActivityFlushInterval = 5 * time.Minute
+	// ActivityLoadBackoff is how long to wait before loading the stored activity again after the first failure. It
+	// doubles with every failure, up to MaximumActivityLoadBackoff.
+	ActivityLoadBackoff        = 30 * time.Second
+	MaximumActivityLoadBackoff = 30 * time.Minute
+)
+
+var InvalidInactivityDaysError error = errors.New("Days must be a positive whole number, e.g. `!inactive 30`.")
+var ActivityNotLoadedError error = errors.New("Member activity is not available yet. Try again later.")
+
+// _activityTracker records member activity. It is nil until the stored activity has been loaded.
+var _activityTracker *activity.Tracker
+var activityTrackerMutex sync.Mutex
+var startActivityFlushJobOnce sync.Once
+
+// loadActivityTracker loads the stored activity. Tests replace it.
+var loadActivityTracker = activity.LoadTracker
+
+// activityTrackerLoading is set while the stored activity is being loaded, and activityTrackerRetryAt is when it may
+// be loaded again after a failure. Both are guarded by activityTrackerMutex.
+var activityTrackerLoading bool
+var activityTrackerRetryAt time.Time
+var activityTrackerBackoff time.Duration
+
+type InactiveCommandPlugin struct{}
+
+// Enabled returns whether or not the InactiveCommandPlugin is enabled.
+func (i *InactiveCommandPlugin) Enabled() bool {
+	return true
+}
+
+// NewInactiveCommandPlugin creates a new InactiveCommandPlugin.
+func NewInactiveCommandPlugin() *InactiveCommandPlugin {
+	return &InactiveCommandPlugin{}
+}
+
+// Name returns the name of the plugin.
+func (i *InactiveCommandPlugin) Name() string {
+	return InactiveCommandPluginName
+}
+
+// Validate validates whether or not we should execute InactiveCommandPlugin on an incoming Discord message.
+func (i *InactiveCommandPlugin) Validate(session *discordgo.Session, message *discordgo.MessageCreate) bool {
+	return strings.Split(message.Content, " ")[0] == "!inactive" && message.ChannelID == discord.AdminNotificationsChannelID
+}
+
+// getActivityTracker returns the activity tracker, loading it from the data store on first use. The load happens
+// without holding the lock, and after a failure it is not tried again until the backoff has passed, so callers such as
+// the message handler are never held up. ActivityNotLoadedError is returned meanwhile.
+func getActivityTracker() (*activity.Tracker, error) {
+	activityTrackerMutex.Lock()
+	if _activityTracker != nil || activityTrackerLoading || time.Now().Before(activityTrackerRetryAt) {
+		defer activityTrackerMutex.Unlock()
+		if _activityTracker == nil {
+			return nil, ActivityNotLoadedError
+		}
+		return _activityTracker, nil
+	}
+	activityTrackerLoading = true
+	activityTrackerMutex.Unlock()
+
+	tracker, err := loadActivityTracker()
+
+	activityTrackerMutex.Lock()
+	defer activityTrackerMutex.Unlock()
+	activityTrackerLoading = false
+	if err != nil {
+		activityTrackerBackoff *= 2
+		if activityTrackerBackoff < ActivityLoadBackoff {
+			activityTrackerBackoff = ActivityLoadBackoff
+		}
+		if activityTrackerBackoff > MaximumActivityLoadBackoff {
+			activityTrackerBackoff = MaximumActivityLoadBackoff
+		}
+		activityTrackerRetryAt = time.Now().Add(activityTrackerBackoff)
+		log.Printf("Failed to load activity, retrying in %s: %v", activityTrackerBackoff, err)
+		return nil, ActivityNotLoadedError
+	}
+	_activityTracker = tracker
+	activityTrackerBackoff = 0
+
+	return _activityTracker, nil
+}
+
+// recordActivity records an activity for a member. Nil members, who are not on the memberlist, are ignored.
+func recordActivity(member *memberlistentity.Member, kind string, date time.Time) {
+	if member == nil {
+		return
+	}
+
+	// Failures to load the stored activity are logged by getActivityTracker.
+	tracker, err := getActivityTracker()
+	if err != nil {
+		return
+	}
+	tracker.Record(*member, kind, date)
+}
+
+// RecordDiscordActivity records a message or voice channel join by a Discord user.
+func RecordDiscordActivity(discordID string, kind string) {
+	recordActivity(getMemberlist().GetMemberByDiscordID(discordID), kind, time.Now())
+}
+
+// StartActivityFlushJob starts a job that periodically saves recorded activity to the data store.
+// Calling it more than once has no effect.
+func StartActivityFlushJob() {
+	startActivityFlushJobOnce.Do(func() {
+		go func() {
+			for {
+				<-time.After(ActivityFlushInterval)
+				if err := FlushActivity(); err != nil {
+					log.Printf("Failed to save activity: %v", err)
+				}
+			}
+		}()
+	})
+}
+
+// FlushActivity saves the recorded activity to the data store, e.g. before shutting down. Nothing is saved if the
+// stored activity was never loaded, as nothing can have been recorded.
+func FlushActivity() error {
+	activityTrackerMutex.Lock()
+	tracker := _activityTracker
+	activityTrackerMutex.Unlock()
+
+	if tracker == nil {
+		return nil
+	}
+
+	return tracker.Flush()
+}
+
+// describeLastActive describes when and how an inactive member was last seen.
+func describeLastActive(member activity.InactiveMember, now time.Time) string {
+	if member.LastActive.IsZero() {
+		return "never seen"
+	}
+
+	days := int(now.Sub(member.LastActive).Hours() / 24)
+	return fmt.Sprintf("%d days ago (%s, %s)", days, member.Kind, member.LastActive.Format("2006-01-02"))
+}
+
+// Execute executes InactiveCommandPlugin on an incoming Discord message.
+func (i *InactiveCommandPlugin) Execute(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	segments := strings.Split(message.Content, " ")
+	days := DEFAULT_INACTIVITY_DAYS
+	if len(segments) > 1 {
+		parsed, err := strconv.Atoi(segments[1])
+		if err != nil || parsed <= 0 {
+			return InvalidInactivityDaysError
+		}
+		days = parsed
+	}
+
+	tracker, err := getActivityTracker()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	inactive := tracker.FindInactive(getMemberlist().GetMembers(), now.AddDate(0, 0, -days))
+	if len(inactive) == 0 {
+		_, err := session.ChannelMessageSendReply(message.ChannelID, fmt.Sprintf("Every ranked member has been active in the last %d days.", days), message.Reference())
+		return err
+	}
+
+	lines := []string{}
+	for _, member := range inactive {
+		lines = append(lines, fmt.Sprintf("%s (%s): %s", member.Member.Name, member.Member.Rank, describeLastActive(member, now)))
+	}
+
+	return sendChunkedMessage(session, message.ChannelID, fmt.Sprintf("**%d ranked members inactive for %d+ days:**", len(inactive), days), lines)
+}
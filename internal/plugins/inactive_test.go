@@ -0,0 +1,41 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/activity"
+)
+
+func TestGetActivityTrackerBacksOffAfterLoadFailure(t *testing.T) {
+	load := loadActivityTracker
+	defer func() {
+		loadActivityTracker = load
+		_activityTracker = nil
+		activityTrackerRetryAt = time.Time{}
+		activityTrackerBackoff = 0
+	}()
+
+	loads := 0
+	loadActivityTracker = func() (*activity.Tracker, error) {
+		loads++
+		return nil, errors.New("storage unavailable")
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := getActivityTracker(); err != ActivityNotLoadedError {
+			t.Fatalf("Expected ActivityNotLoadedError, got %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Expected a single load attempt during the backoff, got %d", loads)
+	}
+	if activityTrackerBackoff != ActivityLoadBackoff {
+		t.Errorf("Expected a backoff of %s, got %s", ActivityLoadBackoff, activityTrackerBackoff)
+	}
+
+	if err := FlushActivity(); err != nil {
+		t.Errorf("Expected nothing to flush before the activity is loaded, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package plugins
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CustomIDSeparator separates the segments of a message component's custom ID.
+const CustomIDSeparator = ":"
+
+// buildCustomID joins a plugin prefix, an action and its arguments into a message component custom ID.
+func buildCustomID(prefix string, action string, args ...string) string {
+	return strings.Join(append([]string{prefix, action}, args...), CustomIDSeparator)
+}
+
+// parseCustomID splits a message component custom ID built by buildCustomID into its prefix, action and arguments.
+func parseCustomID(customID string) (string, string, []string) {
+	segments := strings.Split(customID, CustomIDSeparator)
+	if len(segments) < 2 {
+		return segments[0], "", nil
+	}
+
+	return segments[0], segments[1], segments[2:]
+}
+
+// interactionCustomID returns the custom ID of a message component or modal submit interaction.
+func interactionCustomID(interaction *discordgo.InteractionCreate) string {
+	switch interaction.Type {
+	case discordgo.InteractionMessageComponent:
+		return interaction.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		return interaction.ModalSubmitData().CustomID
+	}
+
+	return ""
+}
+
+// isInteractionFor returns whether an interaction is a message component or modal submit built with the given prefix.
+func isInteractionFor(interaction *discordgo.InteractionCreate, prefix string) bool {
+	customID := interactionCustomID(interaction)
+	if len(customID) == 0 {
+		return false
+	}
+
+	interactionPrefix, _, _ := parseCustomID(customID)
+	return interactionPrefix == prefix
+}
+
+// modalValues returns the values of a modal submit interaction's text inputs, keyed by custom ID.
+func modalValues(interaction *discordgo.InteractionCreate) map[string]string {
+	values := make(map[string]string)
+	for _, component := range interaction.ModalSubmitData().Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, rowComponent := range row.Components {
+			if input, ok := rowComponent.(*discordgo.TextInput); ok {
+				values[input.CustomID] = strings.TrimSpace(input.Value)
+			}
+		}
+	}
+
+	return values
+}
+
+// interactionUser returns the user who triggered an interaction, whether it happened in a guild or a DM.
+func interactionUser(interaction *discordgo.InteractionCreate) *discordgo.User {
+	if interaction.Member != nil && interaction.Member.User != nil {
+		return interaction.Member.User
+	}
+
+	return interaction.User
+}
+
+// resolveComponentMessage replaces the buttons of the message an interaction came from with a closing note.
+func resolveComponentMessage(session *discordgo.Session, interaction *discordgo.InteractionCreate, note string) error {
+	content := note
+	if interaction.Message != nil {
+		content = interaction.Message.Content + "\n" + note
+	}
+
+	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
@@ -1,10 +1,12 @@
 package plugins
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/rankhistory"
 )
 
 const (
@@ -40,7 +42,7 @@ func (m *ManageMemberlistPlugin) Validate(session *discordgo.Session, message *d
 }
 
 func (m *ManageMemberlistPlugin) isValidOperation(operation string) bool {
-	return operation == "add" || operation == "remove" || operation == "update"
+	return operation == "add" || operation == "remove" || operation == "update" || operation == "lint" || operation == "audit" || operation == "history" || operation == "validate" || operation == "export" || operation == "import" || operation == "check"
 }
 
 func getDiscordAndRuneScapeName(segments []string) (string, string, error) {
@@ -74,6 +76,62 @@ func (m *ManageMemberlistPlugin) remove(segments []string) error {
 	return nil
 }
 
+// lint re-reads the memberlist sheet and reports every row that could not be parsed.
+func (m *ManageMemberlistPlugin) lint(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if err := _memberlist.Refresh(); err != nil {
+		return err
+	}
+
+	lines := []string{}
+	if missing := _memberlist.GetSchema().MissingColumns(); len(missing) > 0 {
+		lines = append(lines, fmt.Sprintf("Header is missing columns: %s", strings.Join(missing, ", ")))
+	}
+
+	for _, issue := range _memberlist.GetIssues() {
+		lines = append(lines, fmt.Sprintf("Row %d: %s", issue.Row, issue.Reason))
+	}
+
+	if len(lines) == 0 {
+		_, err := session.ChannelMessageSendReply(message.ChannelID, fmt.Sprintf("No problems found. %d members parsed.", len(_memberlist.GetMembers())), message.Reference())
+		return err
+	}
+
+	return sendChunkedMessage(session, message.ChannelID, fmt.Sprintf("Found %d problems in the memberlist sheet:", len(lines)), lines)
+}
+
+// history shows the promotions and demotions of a member.
+func (m *ManageMemberlistPlugin) history(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if len(args) < 1 {
+		return TooFewArgumentsError
+	}
+
+	member := _memberlist.FindMember(strings.Join(args, " "))
+	if member == nil {
+		return MemberNotFoundError
+	}
+
+	history, err := rankhistory.GetRankHistory(*member)
+	if err != nil {
+		return err
+	}
+
+	if len(history.Changes) == 0 {
+		_, err = session.ChannelMessageSendReply(message.ChannelID, fmt.Sprintf("No rank changes recorded for %s.", member.Name), message.Reference())
+		return err
+	}
+
+	lines := []string{}
+	for _, change := range history.Changes {
+		line := fmt.Sprintf("%s: %s → %s by %s", change.Date, change.FromRank, change.ToRank, change.ChangedBy)
+		if len(change.Reason) > 0 {
+			line += fmt.Sprintf(" (%s)", change.Reason)
+		}
+		lines = append(lines, line)
+	}
+
+	return sendChunkedMessage(session, message.ChannelID, fmt.Sprintf("Rank history for **%s**:", member.Name), lines)
+}
+
 // Execute executes ManageMemberlistPlugin on an incoming Discord message.
 func (m *ManageMemberlistPlugin) Execute(session *discordgo.Session, message *discordgo.MessageCreate) error {
 	segments := strings.Split(message.Content, " ")
@@ -82,7 +140,11 @@ func (m *ManageMemberlistPlugin) Execute(session *discordgo.Session, message *di
 		members := _memberlist.GetMembers()
 		memberString := ""
 		for _, member := range members {
-			memberString += member.Name + " - " + member.Accounts.LPC + "\n"
+			rsn := ""
+			if primary := member.Accounts.Primary(); primary != nil {
+				rsn = primary.RuneScapeName
+			}
+			memberString += member.Name + " - " + rsn + "\n"
 		}
 
 		session.ChannelMessageSendReply(message.ChannelID, memberString, message.Reference())
@@ -91,7 +153,7 @@ func (m *ManageMemberlistPlugin) Execute(session *discordgo.Session, message *di
 
 	operation := segments[1]
 	if !m.isValidOperation(operation) {
-		return InvalidOperationError
+		return InvalidMemberlistOperationError
 	}
 
 	args := segments[2:]
@@ -102,6 +164,20 @@ func (m *ManageMemberlistPlugin) Execute(session *discordgo.Session, message *di
 	case "remove":
 		err = m.remove(args)
 	case "update":
+	case "lint":
+		err = m.lint(session, message)
+	case "audit":
+		err = m.audit(args, session, message)
+	case "history":
+		err = m.history(args, session, message)
+	case "validate":
+		err = m.validate(args, session, message)
+	case "export":
+		err = m.export(args, session, message)
+	case "import":
+		err = m.importMemberlist(args, session, message)
+	case "check":
+		err = m.check(session, message)
 	}
 
 	return err
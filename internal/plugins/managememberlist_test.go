@@ -2,6 +2,7 @@ package plugins
 
 import (
 	"testing"
+	"time"
 )
 
 type GetDiscordAndRuneScapeNameTest struct {
@@ -31,3 +32,18 @@ func TestGetDiscordAndRuneScapeName(t *testing.T) {
 		}
 	}
 }
+
+func TestTakePendingImportExpires(t *testing.T) {
+	addPendingImport("expired", pendingImport{expires: time.Now().Add(-time.Minute)})
+	addPendingImport("pending", pendingImport{version: 3, expires: time.Now().Add(PENDING_IMPORT_TTL)})
+
+	if pending := takePendingImport("expired"); pending != nil {
+		t.Errorf("Expected an expired import to be forgotten, got %+v", pending)
+	}
+	if pending := takePendingImport("pending"); pending == nil || pending.version != 3 {
+		t.Fatalf("Expected the pending import, got %+v", pending)
+	}
+	if pending := takePendingImport("pending"); pending != nil {
+		t.Errorf("Expected an applied import to be forgotten, got %+v", pending)
+	}
+}
@@ -2,9 +2,16 @@ package plugins
 
 import (
 	"fmt"
+	"log"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/jessevdk/go-flags"
+	"github.com/joeydotdev/corgi-discord-bot/internal/activity"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
 	"github.com/joeydotdev/corgi-discord-bot/internal/xptracker"
 )
 
@@ -14,8 +21,113 @@ const (
 
 type ManageXpTrackerPlugin struct{}
 
-// activeXpTrackerEvent is the currently active tracker event.
-var activeXpTrackerEvent *xptracker.XpTrackerEvent
+type xpTrackerStartOpts struct {
+	Account    string `long:"account" description:"Tag of the members' accounts to track" default:"lpc"`
+	Force      bool   `long:"force" description:"Abandon the active event with the same name without recording its results"`
+	Skills     string `long:"skills" description:"Comma separated skills or presets (combat, skilling, all) to track"`
+	Activities string `long:"activities" description:"Comma separated activities and bosses to track, e.g. zulrah,clue_scrolls_all"`
+	Snapshots  int    `long:"snapshot-every" description:"Minutes between snapshots of every participant's gains for charts"`
+}
+
+type xpTrackerListOpts struct {
+	Active bool `long:"active" description:"Only list events that are still active"`
+}
+
+// MAXIMUM_LISTED_XP_TRACKER_EVENTS is how many events !xptracker list shows.
+const MAXIMUM_LISTED_XP_TRACKER_EVENTS = 25
+
+// STORED_XP_TRACKER_EVENTS_TTL is how long the stored events read by commands listing past events are reused.
+const STORED_XP_TRACKER_EVENTS_TTL = 5 * time.Minute
+
+// activeXpTrackerEvents holds the currently active tracker events.
+var activeXpTrackerEvents = xptracker.NewRegistry()
+
+// restoredXpTrackerEvents is whether the active events were restored from storage.
+var restoredXpTrackerEvents bool
+var restoreXpTrackerEventsMutex sync.Mutex
+
+// storedXpTrackerEvents caches every stored event, so that commands such as !profile do not download them all on
+// every use. The cached events are shared and must not be changed.
+var storedXpTrackerEvents []*xptracker.XpTrackerEvent
+var storedXpTrackerEventsAt time.Time
+var storedXpTrackerEventsMutex sync.Mutex
+
+// getStoredXpTrackerEvents returns every stored event, downloading them at most once every STORED_XP_TRACKER_EVENTS_TTL.
+func getStoredXpTrackerEvents() ([]*xptracker.XpTrackerEvent, error) {
+	storedXpTrackerEventsMutex.Lock()
+	defer storedXpTrackerEventsMutex.Unlock()
+
+	if storedXpTrackerEvents != nil && time.Since(storedXpTrackerEventsAt) < STORED_XP_TRACKER_EVENTS_TTL {
+		return storedXpTrackerEvents, nil
+	}
+
+	events, err := xptracker.GetXpTrackerEvents()
+	if err != nil {
+		return nil, err
+	}
+	storedXpTrackerEvents = events
+	storedXpTrackerEventsAt = time.Now()
+
+	return events, nil
+}
+
+// forgetStoredXpTrackerEvents makes the next getStoredXpTrackerEvents download the stored events again. It is called
+// whenever an event starts or ends.
+func forgetStoredXpTrackerEvents() {
+	storedXpTrackerEventsMutex.Lock()
+	defer storedXpTrackerEventsMutex.Unlock()
+
+	storedXpTrackerEvents = nil
+}
+
+// RestoreXpTrackerEvents restores the events still active in storage after a restart. Once they have been restored,
+// later calls, e.g. on reconnecting to Discord, do nothing, as events being stopped are still active in storage.
+func RestoreXpTrackerEvents() error {
+	restoreXpTrackerEventsMutex.Lock()
+	defer restoreXpTrackerEventsMutex.Unlock()
+
+	if restoredXpTrackerEvents {
+		return nil
+	}
+
+	events, err := xptracker.GetActiveXpTrackerEvents()
+	if err != nil {
+		return err
+	}
+	restoredXpTrackerEvents = true
+
+	for _, v := range events {
+		if activeXpTrackerEvents.Get(v.Uuid) != nil {
+			continue
+		}
+		if err := activeXpTrackerEvents.Add(v); err != nil {
+			log.Printf("Failed to restore xp tracker event %s (%s): %v", v.Name, v.Uuid, err)
+			continue
+		}
+		log.Printf("Restored active xp tracker event %s (%s)", v.Name, v.Uuid)
+	}
+
+	return nil
+}
+
+// abandonConflictingXpTrackerEvent abandons, or refuses to replace, the active event with the given short name.
+func abandonConflictingXpTrackerEvent(shortName string, force bool) error {
+	event := activeXpTrackerEvents.Get(shortName)
+	if event == nil {
+		return nil
+	}
+	if !force {
+		return fmt.Errorf("Event %s (%s) is still active. Stop it, choose another name, or use `!xptracker start --force <name>` to abandon it without recording its results.", event.Name, event.Uuid)
+	}
+
+	// The event may have been stopped meanwhile, in which case there is nothing left to abandon.
+	if event = activeXpTrackerEvents.Take(event.Uuid); event == nil {
+		return nil
+	}
+
+	defer forgetStoredXpTrackerEvents()
+	return event.Abandon()
+}
 
 // Enabled returns whether or not the ManageXpTrackerPlugin is enabled.
 func (m *ManageXpTrackerPlugin) Enabled() bool {
@@ -29,7 +141,7 @@ func NewManageXpTrackerPlugin() *ManageXpTrackerPlugin {
 
 // Name returns the name of the plugin.
 func (m *ManageXpTrackerPlugin) Name() string {
-	return PingCommandPluginName
+	return ManageXpTrackerPluginName
 }
 
 // Validate validates whether or not we should execute ManageXpTrackerPlugin on an incoming Discord message.
@@ -38,64 +150,331 @@ func (m *ManageXpTrackerPlugin) Validate(session *discordgo.Session, message *di
 }
 
 func (m *ManageXpTrackerPlugin) isValidOperation(operation string) bool {
-	return operation == "start" || operation == "stop" || operation == "status"
+	return operation == "start" || operation == "stop" || operation == "status" || operation == "retry" || operation == "leaderboard" || operation == "list" || operation == "schedule" || operation == "unschedule" || operation == "history" || operation == "chart"
+}
+
+// describeSkillErrors lists the tracked skills and activities of a participant that could not be read, in a stable order.
+func describeSkillErrors(event *xptracker.XpTrackerEvent, participant xptracker.Participant) string {
+	skills := []string{}
+	for _, skill := range append(append([]string{}, event.GetSkills()...), event.Activities...) {
+		if reason, ok := participant.SkillErrors[skill]; ok {
+			skills = append(skills, fmt.Sprintf("%s (%s)", skill, reason))
+		}
+	}
+
+	return strings.Join(skills, ", ")
+}
+
+// sendSnapshotFailures lists the participants of an event that could not be looked up, and those missing some skills.
+// Active events list the participants that could not be tracked, ended events those without a final snapshot.
+func sendSnapshotFailures(session *discordgo.Session, channelID string, event *xptracker.XpTrackerEvent) error {
+	failed := []string{}
+	partial := []string{}
+	for _, v := range event.Participants {
+		if event.IsFailed(v) {
+			failed = append(failed, fmt.Sprintf("- %s (%s): %s", v.Name, v.RuneScapeName, v.Error))
+		} else if v.IsTracked() && len(v.SkillErrors) > 0 {
+			partial = append(partial, fmt.Sprintf("- %s (%s): %s", v.Name, v.RuneScapeName, describeSkillErrors(event, v)))
+		}
+	}
+
+	if len(failed) > 0 {
+		header := fmt.Sprintf("Could not track %d member(s). Use `!xptracker retry %s` to try them again.", len(failed), event.GetShortName())
+		if !event.IsActive {
+			header = fmt.Sprintf("Could not take a final snapshot of %d member(s), so no gains were recorded for them. Use `!xptracker retry %s` to try them again.", len(failed), event.Uuid)
+		}
+		if err := sendChunkedMessage(session, channelID, header, failed); err != nil {
+			return err
+		}
+	}
+	if len(partial) > 0 {
+		header := fmt.Sprintf("No gains will be recorded in some skills for %d member(s):", len(partial))
+		if err := sendChunkedMessage(session, channelID, header, partial); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (m *ManageXpTrackerPlugin) start(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
-	if activeXpTrackerEvent != nil {
-		return ActiveOngoingEventError
+	if len(args) < 1 {
+		return TooFewArgumentsError
 	}
 
+	opts := &xpTrackerStartOpts{}
+	args, err := flags.ParseArgs(opts, args)
+	if err != nil {
+		return err
+	}
 	if len(args) < 1 {
 		return TooFewArgumentsError
 	}
+	account, err := memberlistentity.NormaliseAccountTag(opts.Account)
+	if err != nil {
+		return err
+	}
+	skills, err := xptracker.ParseSkills(opts.Skills)
+	if err != nil {
+		return err
+	}
+	activities, err := xptracker.ParseActivities(opts.Activities)
+	if err != nil {
+		return err
+	}
+
+	if opts.Snapshots != 0 && opts.Snapshots < xptracker.MINIMUM_SNAPSHOT_INTERVAL {
+		return xptracker.ErrSnapshotIntervalTooShort
+	}
 
 	name := strings.Join(args, " ")
+	shortName := xptracker.ShortNameOf(name)
+	if len(shortName) == 0 {
+		return InvalidEventNameError
+	}
+	// Forcing a start discards another event's results, so only officers may do it.
+	if opts.Force && (message.Member == nil || !isOfficer(message.Member)) {
+		return NotOfficerError
+	}
+	if err := abandonConflictingXpTrackerEvent(shortName, opts.Force); err != nil {
+		return err
+	}
+
+	progress, err := sendProgressMessage(session, message, "Taking a hiscores snapshot of every participant...")
+	if err != nil {
+		return err
+	}
+
 	members := getMemberlist().GetMembers()
-	activeXpTrackerEvent = xptracker.NewXpTrackerEvent(name, members)
-	_, err := session.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Successfully started event. Use `!xptracker status %s` to track the event.", activeXpTrackerEvent.Uuid))
-	return err
+	event := xptracker.NewXpTrackerEvent(name, members, account, skills, activities, opts.Snapshots, hiscores.GetPlayer, progress)
+	forgetStoredXpTrackerEvents()
+	if err := activeXpTrackerEvents.Add(event); err != nil {
+		event.Abandon()
+		return ActiveOngoingEventError
+	}
+	_, err = session.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Successfully started event `%s`. Use `!xptracker status %s` to track the event.", shortName, shortName))
+	if err != nil {
+		return err
+	}
+
+	return sendSnapshotFailures(session, message.ChannelID, event.Copy())
 }
 
-func (m *ManageXpTrackerPlugin) stop(session *discordgo.Session, message *discordgo.MessageCreate) error {
-	if activeXpTrackerEvent == nil {
-		return NoEventError
+// retry re-snapshots the participants of an event that could not be looked up: at the start of active events, or at
+// the end of ended ones.
+func (m *ManageXpTrackerPlugin) retry(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	event, err := resolveActiveXpTrackerEvent(args)
+	if err == EventNotFoundError {
+		event, err = resolveXpTrackerEvent(args)
+	}
+	if err != nil {
+		return err
+	}
+	current := event.Copy()
+	if current.Abandoned {
+		return fmt.Errorf("Event `%s` was abandoned, so it has no results to retry.", current.GetShortName())
 	}
 
-	activeXpTrackerEvent.EndEvent()
-	_, err := session.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Successfully ended event. Use `!xptracker status %s` to see the results.", activeXpTrackerEvent.Uuid))
-	return err
+	failed := current.GetFailedParticipants()
+	if len(failed) == 0 {
+		content := "Every participant is already being tracked."
+		if !current.IsActive {
+			content = "The gains of every participant were already recorded."
+		}
+		_, err := session.ChannelMessageSend(message.ChannelID, content)
+		return err
+	}
+
+	progress, err := sendProgressMessage(session, message, fmt.Sprintf("Retrying %d participant(s)...", len(failed)))
+	if err != nil {
+		return err
+	}
+
+	stillFailed, err := event.Retry(hiscores.GetPlayer, progress)
+	if err != nil {
+		return err
+	}
+	forgetStoredXpTrackerEvents()
+
+	current = event.Copy()
+	content := fmt.Sprintf("Now tracking %d of %d previously failed participant(s).", len(failed)-len(stillFailed), len(failed))
+	if !current.IsActive {
+		recordXpGainActivity(current, failed)
+		content = fmt.Sprintf("Recorded the gains of %d of %d previously failed participant(s).", len(failed)-len(stillFailed), len(failed))
+	}
+	_, err = session.ChannelMessageSend(message.ChannelID, content)
+	if err != nil {
+		return err
+	}
+
+	return sendSnapshotFailures(session, message.ChannelID, current)
 }
 
-func (m *ManageXpTrackerPlugin) status(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
-	var targetEvent *xptracker.XpTrackerEvent
-	var err error
+func (m *ManageXpTrackerPlugin) stop(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	event, err := resolveActiveXpTrackerEvent(args)
+	if err != nil {
+		return err
+	}
+	// Taking the event out of the registry makes sure it is ended only once, even if it is stopped twice at once.
+	if event = activeXpTrackerEvents.Take(event.Uuid); event == nil {
+		return EventNotFoundError
+	}
+
+	progress, err := sendProgressMessage(session, message, "Taking a final hiscores snapshot of every participant...")
+	if err != nil {
+		return err
+	}
+
+	event.EndEvent(hiscores.GetPlayer, progress)
+	forgetStoredXpTrackerEvents()
+	recordXpGainActivity(event, event.Participants)
+	_, err = session.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Successfully ended event `%s`. Use `!xptracker status %s` to see the results.", event.GetShortName(), event.Uuid))
+	if err != nil {
+		return err
+	}
+
+	return sendSnapshotFailures(session, message.ChannelID, event)
+}
+
+// recordXpGainActivity records activity for the given participants of an ended event who gained xp or score.
+func recordXpGainActivity(event *xptracker.XpTrackerEvent, participants []xptracker.Participant) {
+	for _, v := range participants {
+		participant := event.GetParticipant(v.Name)
+		if participant == nil {
+			continue
+		}
+		if xptracker.GetTotalXp(participant.XpGainedTable) > 0 || xptracker.GetTotalXp(participant.ScoreGainedTable) > 0 {
+			recordActivity(getMemberlist().GetMemberByName(participant.Name), activity.KindXpGain, time.Now())
+		}
+	}
+}
+
+// resolveActiveXpTrackerEvent returns the active event addressed by the UUID or short name given as the first
+// argument. If none is given, the only active event is returned.
+func resolveActiveXpTrackerEvent(args []string) (*xptracker.XpTrackerEvent, error) {
+	query := strings.Join(args, " ")
+	if len(strings.TrimSpace(query)) > 0 {
+		if event := activeXpTrackerEvents.Get(query); event != nil {
+			return event, nil
+		}
+		return nil, EventNotFoundError
+	}
 
-	uuid := args[0]
-	if len(uuid) == 0 && activeXpTrackerEvent == nil {
-		return NoEventError
+	active := activeXpTrackerEvents.Active()
+	switch len(active) {
+	case 0:
+		return nil, NoEventError
+	case 1:
+		return active[0], nil
 	}
 
-	if len(uuid) == 0 {
-		targetEvent = activeXpTrackerEvent
-	} else {
-		targetEvent, err = xptracker.GetXpTrackerEventByUUID(uuid)
+	names := []string{}
+	for _, v := range active {
+		names = append(names, fmt.Sprintf("`%s`", v.GetShortName()))
+	}
+
+	return nil, fmt.Errorf("Several events are active. Name one of: %s", strings.Join(names, ", "))
+}
+
+// resolveXpTrackerEvent returns the active or stored event addressed by the UUID or short name given as the first
+// argument. If none is given, the only active event is returned. Active events are returned as a copy, which can be
+// read while the event is ended or retried.
+func resolveXpTrackerEvent(args []string) (*xptracker.XpTrackerEvent, error) {
+	event, err := resolveActiveXpTrackerEvent(args)
+	if err == nil {
+		return event.Copy(), nil
+	}
+	if err != EventNotFoundError {
+		return nil, err
+	}
+
+	query := strings.Join(args, " ")
+	if event, err := xptracker.GetXpTrackerEventByUUID(strings.TrimSpace(query)); err == nil {
+		return event, nil
+	}
+
+	events, err := xptracker.GetXpTrackerEvents()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range xptracker.SortByStartDate(events) {
+		if v.Matches(query) {
+			return v, nil
+		}
+	}
+
+	return nil, EventNotFoundError
+}
+
+func (m *ManageXpTrackerPlugin) list(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	opts := &xpTrackerListOpts{}
+	if _, err := flags.ParseArgs(opts, args); err != nil {
+		return err
+	}
+
+	events := []*xptracker.XpTrackerEvent{}
+	for _, v := range activeXpTrackerEvents.Active() {
+		events = append(events, v.Copy())
+	}
+	if !opts.Active {
+		stored, err := getStoredXpTrackerEvents()
 		if err != nil {
 			return err
 		}
+		events = xptracker.SortByStartDate(stored)
 	}
 
-	if targetEvent == nil {
-		return NoEventError
+	lines := []string{}
+	for i, v := range events {
+		if i >= MAXIMUM_LISTED_XP_TRACKER_EVENTS {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(events)-i))
+			break
+		}
+
+		state := "ended"
+		if v.IsActive {
+			state = "active"
+		}
+		lines = append(lines, fmt.Sprintf("- `%s` %s (%s): %s, started %s, %d participant(s)", v.GetShortName(), v.Name, v.Uuid, state, v.StartDate, len(v.Participants)))
+	}
+
+	if !opts.Active {
+		schedules, err := xptracker.LoadSchedules()
+		if err != nil {
+			return err
+		}
+		for _, v := range schedules {
+			if v.Status == xptracker.ScheduleStatusPending {
+				lines = append(lines, fmt.Sprintf("- `%s` %s: scheduled from %s to %s", v.ShortName, v.Name, v.FormatTime(v.GetStart()), v.FormatTime(v.GetEnd())))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		_, err := session.ChannelMessageSend(message.ChannelID, "There are no events to list.")
+		return err
+	}
+
+	return sendChunkedMessage(session, message.ChannelID, "", lines)
+}
+
+func (m *ManageXpTrackerPlugin) status(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	targetEvent, err := resolveXpTrackerEvent(args)
+	if err != nil {
+		return err
 	}
 
 	_, err = session.ChannelMessageSend(message.ChannelID, fmt.Sprintf(`
 Event Name: %s
+Event Short Name: %s
 Event UUID: %s
 Event Started: %s
 Event Ended: %s
 Event Participants: %d
-		`, targetEvent.Name, targetEvent.Uuid, targetEvent.StartDate, targetEvent.EndDate, len(targetEvent.Participants)))
+Failed Participants: %d
+Tracked Skills: %s
+Tracked Activities: %s
+		`, targetEvent.Name, targetEvent.GetShortName(), targetEvent.Uuid, targetEvent.StartDate, targetEvent.EndDate, len(targetEvent.Participants), len(targetEvent.GetFailedParticipants()), orNone(strings.Join(targetEvent.GetSkills(), ", ")), orNone(strings.Join(targetEvent.Activities, ", "))))
 
 	return err
 }
@@ -109,7 +488,7 @@ func (m *ManageXpTrackerPlugin) Execute(session *discordgo.Session, message *dis
 
 	operation := segments[1]
 	if !m.isValidOperation(operation) {
-		return InvalidOperationError
+		return InvalidXpTrackerOperationError
 	}
 	args := segments[2:]
 
@@ -118,9 +497,23 @@ func (m *ManageXpTrackerPlugin) Execute(session *discordgo.Session, message *dis
 	case "start":
 		err = m.start(args, session, message)
 	case "stop":
-		err = m.stop(session, message)
+		err = m.stop(args, session, message)
 	case "status":
 		err = m.status(args, session, message)
+	case "retry":
+		err = m.retry(args, session, message)
+	case "leaderboard":
+		err = m.leaderboard(args, session, message)
+	case "list":
+		err = m.list(args, session, message)
+	case "schedule":
+		err = m.schedule(args, session, message)
+	case "unschedule":
+		err = m.unschedule(args, session, message)
+	case "history":
+		err = m.history(args, session, message)
+	case "chart":
+		err = m.chart(args, session, message)
 	}
 
 	return err
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
 )
 
 const (
@@ -13,19 +14,6 @@ const (
 )
 
 var (
-	ROLE_IDS []string = []string{
-		"692876249118539817",  // Leader
-		"692879184024043540",  // High Council
-		"692879285417017375",  // Council
-		"817499802148274226",  // Leadership
-		"692879600380018699",  // Officer
-		"692879942777569312",  // Legend
-		"1024119526801023006", // Old School
-		"692880299855446106",  // Veteran
-		"699354924185682031",  // Advanced
-		"692880390440091659",  // Member
-		// "773216677423874048",  // Applicant
-	}
 	EXCLUDED_USER_IDS []string = []string{
 		"223169696055296011", // joey
 	}
@@ -66,7 +54,7 @@ func (p *MassPMCommandPlugin) Execute(session *discordgo.Session, message *disco
 		return TooFewArgumentsError
 	}
 
-	members, err := session.GuildMembers(message.GuildID, "", 1000)
+	members, err := getGuildMembers(session)
 	if err != nil {
 		return err
 	}
@@ -78,32 +66,21 @@ func (p *MassPMCommandPlugin) Execute(session *discordgo.Session, message *disco
 			}
 		}
 
-		hasAttemptedToMessageMember := false
-		for _, role := range member.Roles {
-			if hasAttemptedToMessageMember {
-				break
-			}
-			for _, roleID := range ROLE_IDS {
-				if hasAttemptedToMessageMember {
-					break
-				}
-				if role == roleID {
-					channel, err := session.UserChannelCreate(member.User.ID)
-					if channel == nil || err != nil {
-						fmt.Println("Failed to create channel with member: ", member.User.ID)
-						hasAttemptedToMessageMember = true
-						continue
-					}
-
-					_, err = session.ChannelMessageSend(channel.ID, messageToSend)
-					if err != nil {
-						fmt.Println("Failed to send message to member: ", member.User.ID)
-						fmt.Println("error: ", err)
-					}
-
-					hasAttemptedToMessageMember = true
-				}
-			}
+		rank, _ := memberlist.GetDiscordMemberClanRank(member)
+		if rank == nil || !rank.ReceivesMassPM {
+			return
+		}
+
+		channel, err := session.UserChannelCreate(member.User.ID)
+		if channel == nil || err != nil {
+			fmt.Println("Failed to create channel with member: ", member.User.ID)
+			return
+		}
+
+		_, err = session.ChannelMessageSend(channel.ID, messageToSend)
+		if err != nil {
+			fmt.Println("Failed to send message to member: ", member.User.ID)
+			fmt.Println("error: ", err)
 		}
 	}
 
@@ -0,0 +1,211 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/changerequests"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/rsnwatch"
+)
+
+const (
+	MeCommandPluginName    = "MeCommandPlugin"
+	MeChangeCustomIDPrefix = "mechange"
+)
+
+var InvalidMeOperationError error = errors.New("Invalid operation. Usage: `!me set <lpc|xlpc|teamspeak> <value>`")
+var NotInMemberlistError error = errors.New("You are not on the memberlist. Use `!apply` to join the clan.")
+var UnchangedFieldError error = errors.New("That is already the value on the memberlist.")
+var RSNTooLongError error = fmt.Errorf("RSNs are at most %d characters long.", rsnwatch.MaximumRuneScapeNameLength)
+var ChangeRequestOutdatedError error = errors.New("The memberlist no longer matches this request, so it was not applied. It may already have been approved.")
+
+// ME_FIELDS are the fields members can change with `!me set`.
+var ME_FIELDS []string = []string{memberlistentity.AccountLPC, memberlistentity.AccountXLPC, changerequests.FieldTeamSpeak}
+
+type MeCommandPlugin struct{}
+
+// Enabled returns whether or not the MeCommandPlugin is enabled.
+func (m *MeCommandPlugin) Enabled() bool {
+	return true
+}
+
+// NewMeCommandPlugin creates a new MeCommandPlugin.
+func NewMeCommandPlugin() *MeCommandPlugin {
+	return &MeCommandPlugin{}
+}
+
+// Name returns the name of the plugin.
+func (m *MeCommandPlugin) Name() string {
+	return MeCommandPluginName
+}
+
+// Validate validates whether or not we should execute MeCommandPlugin on an incoming Discord message.
+func (m *MeCommandPlugin) Validate(session *discordgo.Session, message *discordgo.MessageCreate) bool {
+	return strings.Split(message.Content, " ")[0] == "!me"
+}
+
+// parseMeField returns the change request field named by the argument of `!me set`, one of ME_FIELDS.
+func parseMeField(arg string) (string, error) {
+	for _, field := range ME_FIELDS {
+		if strings.EqualFold(arg, field) {
+			return field, nil
+		}
+	}
+
+	return "", InvalidMeOperationError
+}
+
+// verifyRSN checks a requested RSN against the hiscores. RSNs that are not on the hiscores are rejected, while RSNs
+// that could not be checked are returned as a note for the reviewing officer.
+func verifyRSN(rsn string, lookup hiscores.Lookup) (string, error) {
+	if len(rsn) > rsnwatch.MaximumRuneScapeNameLength {
+		return "", RSNTooLongError
+	}
+
+	_, err := lookup(rsn)
+	if errors.Is(err, hiscores.ErrPlayerNotFound) {
+		return "", fmt.Errorf("%s is not on the hiscores. Check the spelling, or wait until the account has a ranked skill.", rsn)
+	}
+	if err != nil {
+		return fmt.Sprintf("%s could not be checked against the hiscores: %v", rsn, err), nil
+	}
+
+	return "", nil
+}
+
+// Execute queues a change to the author's own memberlist entry for officer approval.
+func (m *MeCommandPlugin) Execute(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	segments := strings.Split(message.Content, " ")
+	if len(segments) < 4 || segments[1] != "set" {
+		return InvalidMeOperationError
+	}
+
+	field, err := parseMeField(segments[2])
+	if err != nil {
+		return InvalidMeOperationError
+	}
+	value := strings.TrimSpace(strings.Join(segments[3:], " "))
+	if len(value) == 0 {
+		return InvalidMeOperationError
+	}
+
+	member := getMemberlist().GetMemberByDiscordID(message.Author.ID)
+	if member == nil {
+		return NotInMemberlistError
+	}
+	if changerequests.GetField(*member, field) == value {
+		return UnchangedFieldError
+	}
+
+	request := changerequests.NewChangeRequest(*member, field, value)
+	if field != changerequests.FieldTeamSpeak {
+		note, err := verifyRSN(value, lookupPlayer)
+		if err != nil {
+			return err
+		}
+		if len(note) > 0 {
+			request.Notes = append(request.Notes, note)
+		}
+	}
+
+	if err := request.Save(); err != nil {
+		return err
+	}
+	if err := postChangeRequest(session, request); err != nil {
+		return err
+	}
+
+	_, err = session.ChannelMessageSendReply(message.ChannelID, "Your change has been sent to the officers for approval.", message.Reference())
+	return err
+}
+
+// postChangeRequest posts a change request to the officers with approve and deny buttons.
+func postChangeRequest(session *discordgo.Session, request *changerequests.ChangeRequest) error {
+	content := fmt.Sprintf("<@%s> (**%s**) wants to change their %s from %q to %q.", request.DiscordID, request.MemberName, request.Field, request.OldValue, request.Value)
+	for _, note := range request.Notes {
+		content += "\n⚠️ " + note
+	}
+
+	_, err := session.ChannelMessageSendComplex(discord.ChangeRequestReviewChannelID, &discordgo.MessageSend{
+		Content: content,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Approve",
+						Style:    discordgo.SuccessButton,
+						CustomID: buildCustomID(MeChangeCustomIDPrefix, "approve", request.Uuid),
+					},
+					discordgo.Button{
+						Label:    "Deny",
+						Style:    discordgo.DangerButton,
+						CustomID: buildCustomID(MeChangeCustomIDPrefix, "deny", request.Uuid),
+					},
+				},
+			},
+		},
+	})
+	return err
+}
+
+// ValidateInteraction validates whether or not we should execute MeCommandPlugin on an incoming Discord interaction.
+func (m *MeCommandPlugin) ValidateInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) bool {
+	return isInteractionFor(interaction, MeChangeCustomIDPrefix)
+}
+
+// ExecuteInteraction approves or denies a queued change request. Approved changes are written to the memberlist.
+func (m *MeCommandPlugin) ExecuteInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	if err := requireOfficerInteraction(interaction); err != nil {
+		return err
+	}
+
+	_, action, args := parseCustomID(interactionCustomID(interaction))
+	if len(args) < 1 {
+		return TooFewArgumentsError
+	}
+	request, err := changerequests.GetChangeRequestByUUID(args[0])
+	if err != nil {
+		return err
+	}
+	user := interactionUser(interaction)
+
+	switch action {
+	case "approve":
+		if err := request.Review(changerequests.StatusApproved, user.String()); err != nil {
+			return err
+		}
+		// The change is applied under the memberlist lock, so only one of two concurrent approvals can apply it.
+		err := getMemberlist().ModifyMember(request.MemberKey, request.Apply)
+		if errors.Is(err, memberlistentity.ErrMemberNotInMemberlist) {
+			return MemberNotFoundError
+		}
+		if errors.Is(err, changerequests.ErrChangeRequestOutdated) {
+			return ChangeRequestOutdatedError
+		}
+		if err != nil {
+			return err
+		}
+		if err := request.Save(); err != nil {
+			log.Printf("Failed to save approved change request %s: %v", request.Uuid, err)
+		}
+		notifyUser(session, request.DiscordID, fmt.Sprintf("Your %s was updated to %q.", request.Field, request.Value))
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("✅ Approved by %s.", user.String()))
+	case "deny":
+		if err := request.Review(changerequests.StatusDenied, user.String()); err != nil {
+			return err
+		}
+		if err := request.Save(); err != nil {
+			return err
+		}
+		notifyUser(session, request.DiscordID, fmt.Sprintf("Your request to change your %s to %q was denied. Ask an officer for details.", request.Field, request.Value))
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("❌ Denied by %s.", user.String()))
+	}
+
+	return InvalidOperationError
+}
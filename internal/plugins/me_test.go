@@ -0,0 +1,61 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/changerequests"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func TestParseMeField(t *testing.T) {
+	t.Parallel()
+
+	fields := map[string]string{
+		"lpc":       memberlistentity.AccountLPC,
+		"XLPC":      memberlistentity.AccountXLPC,
+		"TeamSpeak": changerequests.FieldTeamSpeak,
+	}
+	for arg, expected := range fields {
+		field, err := parseMeField(arg)
+		if err != nil || field != expected {
+			t.Errorf("Expected %s to be parsed as %s, got %s and %v", arg, expected, field, err)
+		}
+	}
+
+	for _, arg := range []string{"main", "iron", "rank", ""} {
+		if _, err := parseMeField(arg); err != InvalidMeOperationError {
+			t.Errorf("Expected %q to be rejected, got %v", arg, err)
+		}
+	}
+}
+
+func TestVerifyRSN(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(rsn string) (*hiscores.Player, error) {
+		switch rsn {
+		case "missing":
+			return nil, hiscores.ErrPlayerNotFound
+		case "flaky":
+			return nil, fmt.Errorf("%w: timeout", hiscores.ErrHiscoresUnavailable)
+		}
+		return &hiscores.Player{RuneScapeName: rsn}, nil
+	}
+
+	if note, err := verifyRSN("bender life", lookup); err != nil || len(note) > 0 {
+		t.Errorf("Expected a ranked RSN to pass without a note, got %q and %v", note, err)
+	}
+	if _, err := verifyRSN("missing", lookup); err == nil {
+		t.Errorf("Expected an RSN missing from the hiscores to be rejected")
+	}
+	if note, err := verifyRSN("flaky", lookup); err != nil || !strings.Contains(note, "could not be checked") {
+		t.Errorf("Expected an unchecked RSN to pass with a note, got %q and %v", note, err)
+	}
+	if _, err := verifyRSN(strings.Repeat("a", 13), lookup); !errors.Is(err, RSNTooLongError) {
+		t.Errorf("Expected RSNTooLongError, got %v", err)
+	}
+}
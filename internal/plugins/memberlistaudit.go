@@ -0,0 +1,195 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jessevdk/go-flags"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+// GUILD_MEMBERS_PAGE_SIZE is the largest page of guild members Discord returns at once.
+const GUILD_MEMBERS_PAGE_SIZE = 1000
+
+var AuditFixOutsideAdminChannelError error = errors.New("`--fix` can only be used in the admin notifications channel.")
+
+type memberlistAuditOpts struct {
+	Fix bool `long:"fix" description:"Apply the memberlist rank to each mismatched member's Discord roles"`
+}
+
+// rankMismatch is a member whose memberlist rank differs from their highest Discord clan role.
+type rankMismatch struct {
+	Member        memberlistentity.Member
+	DiscordMember *discordgo.Member
+	// SheetRank is nil when the memberlist rank is not one of memberlist.RANKS.
+	SheetRank *memberlistentity.Rank
+	// DiscordRank is nil when the Discord member has no clan role.
+	DiscordRank *memberlistentity.Rank
+}
+
+// memberlistAudit is the result of comparing the memberlist against the Discord guild.
+type memberlistAudit struct {
+	Mismatches       []rankMismatch
+	MissingFromSheet []*discordgo.Member
+	MissingFromGuild []memberlistentity.Member
+}
+
+// getGuildMembers returns every member of the guild, fetching them a page at a time.
+func getGuildMembers(session *discordgo.Session) ([]*discordgo.Member, error) {
+	members := []*discordgo.Member{}
+	after := ""
+	for {
+		page, err := session.GuildMembers(discord.GuildID, after, GUILD_MEMBERS_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, page...)
+		if len(page) < GUILD_MEMBERS_PAGE_SIZE {
+			return members, nil
+		}
+		after = page[len(page)-1].User.ID
+	}
+}
+
+// auditMemberlist compares memberlist ranks with the clan roles of the guild's members.
+func auditMemberlist(members []memberlistentity.Member, guildMembers []*discordgo.Member) memberlistAudit {
+	audit := memberlistAudit{}
+	guildMembersByID := make(map[string]*discordgo.Member)
+	for _, guildMember := range guildMembers {
+		if guildMember == nil || guildMember.User == nil {
+			continue
+		}
+		guildMembersByID[guildMember.User.ID] = guildMember
+	}
+
+	sheetDiscordIDs := make(map[string]bool)
+	for _, member := range members {
+		guildMember, ok := guildMembersByID[member.DiscordID]
+		if member.IsDeparted() && !ok {
+			continue
+		}
+		if len(member.DiscordID) == 0 || !ok {
+			audit.MissingFromGuild = append(audit.MissingFromGuild, member)
+			continue
+		}
+		sheetDiscordIDs[member.DiscordID] = true
+
+		sheetRank := memberlistentity.GetRankByName(member.Rank)
+		discordRank, _ := memberlistentity.GetDiscordMemberClanRank(guildMember)
+		if sheetRank != nil && discordRank != nil && sheetRank.RoleID == discordRank.RoleID {
+			continue
+		}
+
+		audit.Mismatches = append(audit.Mismatches, rankMismatch{
+			Member:        member,
+			DiscordMember: guildMember,
+			SheetRank:     sheetRank,
+			DiscordRank:   discordRank,
+		})
+	}
+
+	for _, guildMember := range guildMembers {
+		if guildMember == nil || guildMember.User == nil || sheetDiscordIDs[guildMember.User.ID] {
+			continue
+		}
+		if rank, _ := memberlistentity.GetDiscordMemberClanRank(guildMember); rank != nil {
+			audit.MissingFromSheet = append(audit.MissingFromSheet, guildMember)
+		}
+	}
+
+	return audit
+}
+
+// applySheetRank replaces a Discord member's clan roles with the role of their memberlist rank.
+func applySheetRank(session *discordgo.Session, mismatch rankMismatch) error {
+	if mismatch.SheetRank == nil {
+		return fmt.Errorf("unknown memberlist rank %q", mismatch.Member.Rank)
+	}
+
+	return setClanRank(session, mismatch.DiscordMember, *mismatch.SheetRank)
+}
+
+func rankName(rank *memberlistentity.Rank) string {
+	if rank == nil {
+		return "none"
+	}
+
+	return rank.Name
+}
+
+// audit reports rank mismatches between the memberlist and Discord, optionally fixing the Discord roles.
+func (m *ManageMemberlistPlugin) audit(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	opts := &memberlistAuditOpts{}
+	if _, err := flags.ParseArgs(opts, args); err != nil {
+		return err
+	}
+
+	if opts.Fix && message.ChannelID != discord.AdminNotificationsChannelID {
+		return AuditFixOutsideAdminChannelError
+	}
+
+	guildMembers, err := getGuildMembers(session)
+	if err != nil {
+		return err
+	}
+
+	audit := auditMemberlist(_memberlist.GetMembers(), guildMembers)
+
+	mismatchLines := []string{}
+	fixed := 0
+	for _, mismatch := range audit.Mismatches {
+		line := fmt.Sprintf("%s (%s): memberlist says %s, Discord says %s", mismatch.Member.Name, mismatch.DiscordMember.User.String(), mismatch.Member.Rank, rankName(mismatch.DiscordRank))
+		if opts.Fix {
+			if err := applySheetRank(session, mismatch); err != nil {
+				log.Printf("Failed to fix rank of %s: %v", mismatch.Member.Name, err)
+				line += fmt.Sprintf(" - not fixed: %v", err)
+			} else {
+				fixed++
+				line += " - fixed"
+			}
+		}
+		mismatchLines = append(mismatchLines, line)
+	}
+
+	missingFromSheetLines := []string{}
+	for _, guildMember := range audit.MissingFromSheet {
+		rank, _ := memberlistentity.GetDiscordMemberClanRank(guildMember)
+		missingFromSheetLines = append(missingFromSheetLines, fmt.Sprintf("%s (%s)", guildMember.User.String(), rankName(rank)))
+	}
+
+	missingFromGuildLines := []string{}
+	for _, member := range audit.MissingFromGuild {
+		missingFromGuildLines = append(missingFromGuildLines, fmt.Sprintf("%s (%s)", member.Name, member.Rank))
+	}
+
+	summary := fmt.Sprintf("Audit complete: %d rank mismatches, %d Discord members missing from the memberlist, %d memberlist entries not in Discord.", len(audit.Mismatches), len(audit.MissingFromSheet), len(audit.MissingFromGuild))
+	if opts.Fix {
+		summary += fmt.Sprintf(" Fixed %d of %d mismatches.", fixed, len(audit.Mismatches))
+	}
+	if _, err := session.ChannelMessageSendReply(message.ChannelID, summary, message.Reference()); err != nil {
+		return err
+	}
+
+	sections := []struct {
+		header string
+		lines  []string
+	}{
+		{"**Rank mismatches:**", mismatchLines},
+		{"**Discord members with clan roles missing from the memberlist:**", missingFromSheetLines},
+		{"**Memberlist entries not in Discord:**", missingFromGuildLines},
+	}
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+		if err := sendChunkedMessage(session, message.ChannelID, section.header, section.lines); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
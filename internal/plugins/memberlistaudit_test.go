@@ -0,0 +1,44 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func TestAuditMemberlist(t *testing.T) {
+	t.Parallel()
+
+	memberRole := memberlistentity.GetRankByName("Member").RoleID
+	veteranRole := memberlistentity.GetRankByName("Veteran").RoleID
+
+	members := []memberlistentity.Member{
+		{Name: "in sync", DiscordID: "1", Rank: "Member"},
+		{Name: "promoted", DiscordID: "2", Rank: "veteran"},
+		{Name: "left", DiscordID: "3", Rank: "Member"},
+		{Name: "no discord", Rank: "Member"},
+		{Name: "departed", DiscordID: "6", Rank: "Departed"},
+	}
+	guildMembers := []*discordgo.Member{
+		{User: &discordgo.User{ID: "1"}, Roles: []string{memberRole}},
+		{User: &discordgo.User{ID: "2"}, Roles: []string{memberRole}},
+		{User: &discordgo.User{ID: "4"}, Roles: []string{veteranRole}},
+		{User: &discordgo.User{ID: "5"}},
+	}
+
+	audit := auditMemberlist(members, guildMembers)
+
+	if len(audit.Mismatches) != 1 || audit.Mismatches[0].Member.Name != "promoted" {
+		t.Fatalf("Expected a single mismatch for promoted, got %+v", audit.Mismatches)
+	}
+	if audit.Mismatches[0].SheetRank.RoleID != veteranRole || audit.Mismatches[0].DiscordRank.RoleID != memberRole {
+		t.Errorf("Expected Veteran on the sheet and Member on Discord, got %+v", audit.Mismatches[0])
+	}
+	if len(audit.MissingFromSheet) != 1 || audit.MissingFromSheet[0].User.ID != "4" {
+		t.Errorf("Expected Discord member 4 to be missing from the sheet, got %+v", audit.MissingFromSheet)
+	}
+	if len(audit.MissingFromGuild) != 2 {
+		t.Errorf("Expected 2 memberlist entries missing from the guild, got %d", len(audit.MissingFromGuild))
+	}
+}
@@ -0,0 +1,50 @@
+package plugins
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+// sheetRowLink links to a sheet row without Discord unfurling a preview of the sheet.
+func sheetRowLink(row int) string {
+	if row <= 0 {
+		return "unknown row"
+	}
+
+	return fmt.Sprintf("[row %d](<%s>)", row, memberlistentity.SheetRowURL(row))
+}
+
+// check re-reads the memberlist sheet and reports integrity problems, each linked to its sheet row.
+func (m *ManageMemberlistPlugin) check(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if err := _memberlist.Refresh(); err != nil {
+		return err
+	}
+
+	guildMembers, err := getGuildMembers(session)
+	if err != nil {
+		return err
+	}
+	guildMemberIDs := make(map[string]bool)
+	for _, guildMember := range guildMembers {
+		if guildMember != nil && guildMember.User != nil {
+			guildMemberIDs[guildMember.User.ID] = true
+		}
+	}
+
+	lines := []string{}
+	for _, issue := range _memberlist.GetIssues() {
+		lines = append(lines, fmt.Sprintf("%s: %s", sheetRowLink(issue.Row), issue.Reason))
+	}
+	for _, finding := range memberlistentity.CheckMembers(_memberlist.GetMembers(), guildMemberIDs) {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", sheetRowLink(finding.Row), finding.Member.Name, finding.Problem))
+	}
+
+	if len(lines) == 0 {
+		_, err := session.ChannelMessageSendReply(message.ChannelID, fmt.Sprintf("No problems found in %d memberlist entries.", len(_memberlist.GetMembers())), message.Reference())
+		return err
+	}
+
+	return sendChunkedMessage(session, message.ChannelID, fmt.Sprintf("Found %d problems in the memberlist:", len(lines)), lines)
+}
@@ -0,0 +1,297 @@
+package plugins
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+const (
+	ManageMemberlistCustomIDPrefix = "memberlist"
+	// MAXIMUM_IMPORT_SIZE is the largest memberlist file accepted by `!memberlist import`, in bytes.
+	MAXIMUM_IMPORT_SIZE = 1 << 20
+	// MAXIMUM_DIFF_PREVIEW_LINES is how many changes are listed in an import preview.
+	MAXIMUM_DIFF_PREVIEW_LINES = 50
+	// PENDING_IMPORT_TTL is how long an import preview can be applied for.
+	PENDING_IMPORT_TTL = 15 * time.Minute
+)
+
+var NoImportAttachmentError error = errors.New("Attach a .csv or .json memberlist export to import it.")
+var ImportTooLargeError error = fmt.Errorf("Memberlist imports must be smaller than %d bytes.", MAXIMUM_IMPORT_SIZE)
+var ImportExpiredError error = errors.New("This import is no longer pending. Run `!memberlist import` again.")
+var ImportOutdatedError error = errors.New("The memberlist has changed since this import was previewed. Run `!memberlist import` again.")
+
+// pendingImport is an import awaiting confirmation.
+type pendingImport struct {
+	// members are the reconciled members that replace the memberlist.
+	members []memberlistentity.Member
+	// version is the memberlist version the import was reconciled against.
+	version int
+	expires time.Time
+}
+
+// pendingImports are imports awaiting confirmation, by import ID.
+var pendingImports = make(map[string]pendingImport)
+var pendingImportsMutex sync.Mutex
+
+// export uploads the memberlist as a CSV or JSON attachment. Only officers, or anyone in the admin notifications channel,
+// can export it.
+func (m *ManageMemberlistPlugin) export(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if message.ChannelID != discord.AdminNotificationsChannelID && (message.Member == nil || !isOfficer(message.Member)) {
+		return NotOfficerError
+	}
+
+	format := memberlistentity.FormatCSV
+	if len(args) > 0 {
+		format = args[0]
+	}
+
+	data, err := _memberlist.Export(format)
+	if err != nil {
+		return err
+	}
+
+	contentType := "text/csv"
+	if format == memberlistentity.FormatJSON {
+		contentType = "application/json"
+	}
+
+	_, err = session.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+		Content:   fmt.Sprintf("Memberlist export (%d members):", len(_memberlist.GetMembers())),
+		Reference: message.Reference(),
+		Files: []*discordgo.File{
+			{
+				Name:        "memberlist." + format,
+				ContentType: contentType,
+				Reader:      bytes.NewReader(data),
+			},
+		},
+	})
+	return err
+}
+
+// downloadAttachment downloads a Discord attachment, refusing files larger than MAXIMUM_IMPORT_SIZE.
+func downloadAttachment(attachment *discordgo.MessageAttachment) ([]byte, error) {
+	if attachment.Size > MAXIMUM_IMPORT_SIZE {
+		return nil, ImportTooLargeError
+	}
+
+	resp, err := http.Get(attachment.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed to download %s: %s", attachment.Filename, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MAXIMUM_IMPORT_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MAXIMUM_IMPORT_SIZE {
+		return nil, ImportTooLargeError
+	}
+
+	return data, nil
+}
+
+// describeDiff renders the changes an import would make, one line per member.
+func describeDiff(diff memberlistentity.MemberlistDiff) []string {
+	lines := []string{}
+	for _, member := range diff.Added {
+		lines = append(lines, fmt.Sprintf("+ %s (%s)", member.Name, member.Rank))
+	}
+	for _, member := range diff.Removed {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", member.Name, member.Rank))
+	}
+	for _, change := range diff.Changed {
+		fields := []string{}
+		for _, field := range change.Fields {
+			fields = append(fields, fmt.Sprintf("%s: %q → %q", field, memberField(change.Before, field), memberField(change.After, field)))
+		}
+		lines = append(lines, fmt.Sprintf("~ %s: %s", change.Before.Name, strings.Join(fields, ", ")))
+	}
+
+	return lines
+}
+
+// memberField returns the value of a member field by its column key.
+func memberField(member memberlistentity.Member, field string) string {
+	switch field {
+	case memberlistentity.ColumnName:
+		return member.Name
+	case memberlistentity.ColumnRank:
+		return member.Rank
+	case memberlistentity.ColumnDiscordID:
+		return member.DiscordID
+	case memberlistentity.ColumnTeamSpeakID:
+		return member.TeamSpeakID
+	case memberlistentity.ColumnUuid:
+		return member.Uuid
+	case memberlistentity.ColumnPrimaryAccount:
+		return member.PrimaryAccountTag()
+	}
+
+	return member.GetAccount(field)
+}
+
+// importMemberlist validates an attached memberlist export and previews its changes, pending confirmation.
+func (m *ManageMemberlistPlugin) importMemberlist(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if message.ChannelID != discord.AdminNotificationsChannelID {
+		return AdminChannelOnlyError
+	}
+	if len(message.Attachments) == 0 {
+		return NoImportAttachmentError
+	}
+
+	attachment := message.Attachments[0]
+	format := strings.TrimPrefix(strings.ToLower(path.Ext(attachment.Filename)), ".")
+	if len(args) > 0 {
+		format = args[0]
+	}
+
+	data, err := downloadAttachment(attachment)
+	if err != nil {
+		return err
+	}
+
+	members, issues, err := memberlistentity.ParseImport(format, data)
+	if err != nil {
+		return err
+	}
+
+	problems := memberlistentity.ValidateMembers(members)
+	for _, issue := range issues {
+		problems = append(problems, fmt.Sprintf("row %d: %s", issue.Row, issue.Reason))
+	}
+	if len(problems) > 0 {
+		if err := sendChunkedMessage(session, message.ChannelID, fmt.Sprintf("Import rejected, %d problems found:", len(problems)), problems); err != nil {
+			return err
+		}
+		return errors.New("Fix the problems above and try again.")
+	}
+
+	// The version is read first, so a change made while reconciling rejects the import rather than being overwritten.
+	version := _memberlist.Version()
+	reconciled, diff := memberlistentity.ReconcileImport(_memberlist.GetMembers(), members)
+	if diff.IsEmpty() {
+		_, err = session.ChannelMessageSendReply(message.ChannelID, "The import matches the current memberlist. Nothing to do.", message.Reference())
+		return err
+	}
+
+	lines := describeDiff(diff)
+	if len(lines) > MAXIMUM_DIFF_PREVIEW_LINES {
+		lines = append(lines[:MAXIMUM_DIFF_PREVIEW_LINES], fmt.Sprintf("... and %d more", len(lines)-MAXIMUM_DIFF_PREVIEW_LINES))
+	}
+	if err := sendChunkedMessage(session, message.ChannelID, "**Import preview:**", lines); err != nil {
+		return err
+	}
+
+	importID := uuid.New().String()
+	addPendingImport(importID, pendingImport{members: reconciled, version: version, expires: time.Now().Add(PENDING_IMPORT_TTL)})
+
+	_, err = session.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("Import of %d members: %d added, %d removed, %d changed. Apply it to the memberlist?", len(reconciled), len(diff.Added), len(diff.Removed), len(diff.Changed)),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Apply",
+						Style:    discordgo.DangerButton,
+						CustomID: buildCustomID(ManageMemberlistCustomIDPrefix, "import_apply", importID),
+					},
+					discordgo.Button{
+						Label:    "Cancel",
+						Style:    discordgo.SecondaryButton,
+						CustomID: buildCustomID(ManageMemberlistCustomIDPrefix, "import_cancel", importID),
+					},
+				},
+			},
+		},
+	})
+	return err
+}
+
+// prunePendingImports forgets expired imports. The caller must hold pendingImportsMutex.
+func prunePendingImports(now time.Time) {
+	for importID, v := range pendingImports {
+		if now.After(v.expires) {
+			delete(pendingImports, importID)
+		}
+	}
+}
+
+// addPendingImport records an import awaiting confirmation.
+func addPendingImport(importID string, pending pendingImport) {
+	pendingImportsMutex.Lock()
+	defer pendingImportsMutex.Unlock()
+
+	prunePendingImports(time.Now())
+	pendingImports[importID] = pending
+}
+
+// takePendingImport removes a pending import and returns it, or nil if there is no such import or it has expired.
+func takePendingImport(importID string) *pendingImport {
+	pendingImportsMutex.Lock()
+	defer pendingImportsMutex.Unlock()
+
+	prunePendingImports(time.Now())
+	pending, ok := pendingImports[importID]
+	if !ok {
+		return nil
+	}
+	delete(pendingImports, importID)
+
+	return &pending
+}
+
+// ValidateInteraction validates whether or not we should execute ManageMemberlistPlugin on an incoming Discord interaction.
+func (m *ManageMemberlistPlugin) ValidateInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) bool {
+	return isInteractionFor(interaction, ManageMemberlistCustomIDPrefix)
+}
+
+// ExecuteInteraction applies or cancels a pending memberlist import.
+func (m *ManageMemberlistPlugin) ExecuteInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	_, action, args := parseCustomID(interactionCustomID(interaction))
+	if len(args) < 1 {
+		return TooFewArgumentsError
+	}
+	if err := requireOfficerInteraction(interaction); err != nil {
+		return err
+	}
+	user := interactionUser(interaction)
+
+	switch action {
+	case "import_apply":
+		pending := takePendingImport(args[0])
+		if pending == nil {
+			return ImportExpiredError
+		}
+		if err := _memberlist.ReplaceMembers(pending.members, pending.version); err != nil {
+			if errors.Is(err, memberlistentity.ErrMemberlistChanged) {
+				resolveComponentMessage(session, interaction, "Not applied: the memberlist changed after this preview.")
+				return ImportOutdatedError
+			}
+			return err
+		}
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("✅ Applied by %s.", user.String()))
+	case "import_cancel":
+		takePendingImport(args[0])
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("Cancelled by %s.", user.String()))
+	}
+
+	return InvalidOperationError
+}
@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+var InvalidValidateAccountError error = errors.New("Invalid account. Give account tags such as lpc, xlpc or main, or all.")
+
+// parseValidateAccounts returns the account tags selected by the arguments of `!memberlist validate`.
+// Nil is returned for all, which validates every account of every member.
+func parseValidateAccounts(args []string) ([]string, error) {
+	if len(args) == 0 || args[0] == "all" {
+		return nil, nil
+	}
+
+	accounts := []string{}
+	for _, arg := range args {
+		account, err := memberlistentity.NormaliseAccountTag(arg)
+		if err != nil {
+			return nil, InvalidValidateAccountError
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// validate looks every member's RSNs up on the hiscores and reports the ones that could not be validated.
+func (m *ManageMemberlistPlugin) validate(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	accounts, err := parseValidateAccounts(args)
+	if err != nil {
+		return err
+	}
+
+	progress, err := sendProgressMessage(session, message, "Validating RSNs against the hiscores...")
+	if err != nil {
+		return err
+	}
+
+	invalid := _memberlist.ValidateRSNs(accounts, lookupPlayer, progress)
+
+	missingLines := []string{}
+	notFoundLines := []string{}
+	unavailableLines := []string{}
+	for _, v := range invalid {
+		switch {
+		case v.Err == memberlistentity.ErrMissingRSN:
+			missingLines = append(missingLines, fmt.Sprintf("%s (%s)", v.Member.Name, v.Account))
+		case errors.Is(v.Err, hiscores.ErrPlayerNotFound):
+			notFoundLines = append(notFoundLines, fmt.Sprintf("%s (%s): %s", v.Member.Name, v.Account, v.RuneScapeName))
+		default:
+			unavailableLines = append(unavailableLines, fmt.Sprintf("%s (%s): %s - %v", v.Member.Name, v.Account, v.RuneScapeName, v.Err))
+		}
+	}
+
+	if len(invalid) == 0 {
+		_, err = session.ChannelMessageSend(message.ChannelID, "All RSNs are on the hiscores.")
+		return err
+	}
+
+	sections := []struct {
+		header string
+		lines  []string
+	}{
+		{"**No RSN on the memberlist:**", missingLines},
+		{"**Not on the hiscores:**", notFoundLines},
+		{"**Could not be checked, hiscores unavailable:**", unavailableLines},
+	}
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+		if err := sendChunkedMessage(session, message.ChannelID, section.header, section.lines); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
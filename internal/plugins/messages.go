@@ -0,0 +1,76 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// PROGRESS_UPDATE_STEP is how many hiscores lookups finish between progress message edits.
+const PROGRESS_UPDATE_STEP = 10
+
+// MAXIMUM_MESSAGE_LENGTH is kept below Discord's 2000 character limit to leave room for formatting.
+const MAXIMUM_MESSAGE_LENGTH = 1900
+
+// chunkLines joins lines into messages that each fit within a single Discord message.
+func chunkLines(header string, lines []string) []string {
+	messages := []string{}
+	current := header
+	for _, line := range lines {
+		if len(current)+len(line)+1 > MAXIMUM_MESSAGE_LENGTH && len(current) > 0 {
+			messages = append(messages, current)
+			current = ""
+		}
+		if len(current) > 0 {
+			current += "\n"
+		}
+		current += line
+	}
+
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+
+	return messages
+}
+
+// sendChunkedMessage sends lines to a channel, split over as many messages as needed.
+func sendChunkedMessage(session *discordgo.Session, channelID string, header string, lines []string) error {
+	for _, content := range chunkLines(header, lines) {
+		if _, err := session.ChannelMessageSend(channelID, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyUser sends a user a DM. Failures are logged, as many users do not accept DMs.
+func notifyUser(session *discordgo.Session, discordID string, content string) {
+	channel, err := session.UserChannelCreate(discordID)
+	if err == nil {
+		_, err = session.ChannelMessageSend(channel.ID, content)
+	}
+	if err != nil {
+		log.Printf("Failed to notify %s: %v", discordID, err)
+	}
+}
+
+// sendProgressMessage replies to a message with a label and returns a function that edits the reply to show progress.
+func sendProgressMessage(session *discordgo.Session, message *discordgo.MessageCreate, label string) (func(done int, total int), error) {
+	progressMessage, err := session.ChannelMessageSendReply(message.ChannelID, label, message.Reference())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(done int, total int) {
+		if done%PROGRESS_UPDATE_STEP != 0 && done != total {
+			return
+		}
+		_, err := session.ChannelMessageEdit(progressMessage.ChannelID, progressMessage.ID, fmt.Sprintf("%s %d/%d", label, done, total))
+		if err != nil {
+			log.Printf("Failed to update progress: %v", err)
+		}
+	}, nil
+}
@@ -110,7 +110,7 @@ func getAllTerrorMembers(session *discordgo.Session) ([]*discordgo.Member, error
 			continue
 		}
 		rank, _ := memberlist.GetDiscordMemberClanRank(member)
-		if rank == nil {
+		if rank == nil || !rank.IsMember {
 			continue
 		}
 
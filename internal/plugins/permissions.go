@@ -0,0 +1,36 @@
+package plugins
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+var NotOfficerError error = errors.New("Only officers can do that.")
+var RankAboveOwnError error = errors.New("You can only give ranks below your own.")
+
+// isOfficer returns whether a Discord member holds an officer rank.
+func isOfficer(guildMember *discordgo.Member) bool {
+	rank, _ := memberlistentity.GetDiscordMemberClanRank(guildMember)
+	return rank != nil && rank.IsOfficer
+}
+
+// requireOfficerInteraction returns NotOfficerError unless the user behind a guild interaction holds an officer rank.
+func requireOfficerInteraction(interaction *discordgo.InteractionCreate) error {
+	if interaction.Member == nil || !isOfficer(interaction.Member) {
+		return NotOfficerError
+	}
+
+	return nil
+}
+
+// requireRankAbove returns RankAboveOwnError unless a Discord member's clan rank is above the given rank.
+func requireRankAbove(guildMember *discordgo.Member, rank memberlistentity.Rank) error {
+	actorRank, _ := memberlistentity.GetDiscordMemberClanRank(guildMember)
+	if actorRank == nil || !actorRank.Above(rank) {
+		return RankAboveOwnError
+	}
+
+	return nil
+}
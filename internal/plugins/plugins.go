@@ -9,3 +9,11 @@ type Plugin interface {
 	Execute(session *discordgo.Session, message *discordgo.MessageCreate) error
 	Enabled() bool
 }
+
+// InteractionPlugin is an interface that all plugins handling Discord interactions, such as button clicks, must implement.
+type InteractionPlugin interface {
+	Name() string
+	ValidateInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) bool
+	ExecuteInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) error
+	Enabled() bool
+}
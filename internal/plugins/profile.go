@@ -0,0 +1,206 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/attendance"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/xptracker"
+)
+
+const (
+	ProfileCommandPluginName = "ProfileCommandPlugin"
+	PROFILE_COLOR            = 0xfcd34d
+	// MAXIMUM_PROFILE_EVENTS is how many xp tracker events are listed on a profile.
+	MAXIMUM_PROFILE_EVENTS = 5
+)
+
+// PROFILE_SKILLS are the skills shown for each account on a profile.
+var PROFILE_SKILLS []string = []string{"attack", "strength", "defence", "hitpoints", "ranged", "magic", "prayer"}
+
+type ProfileCommandPlugin struct{}
+
+// Enabled returns whether or not the ProfileCommandPlugin is enabled.
+func (p *ProfileCommandPlugin) Enabled() bool {
+	return true
+}
+
+// NewProfileCommandPlugin creates a new ProfileCommandPlugin.
+func NewProfileCommandPlugin() *ProfileCommandPlugin {
+	return &ProfileCommandPlugin{}
+}
+
+// Name returns the name of the plugin.
+func (p *ProfileCommandPlugin) Name() string {
+	return ProfileCommandPluginName
+}
+
+// Validate validates whether or not we should execute ProfileCommandPlugin on an incoming Discord message.
+func (p *ProfileCommandPlugin) Validate(session *discordgo.Session, message *discordgo.MessageCreate) bool {
+	return strings.Split(message.Content, " ")[0] == "!profile"
+}
+
+// describeDiscordRoles lists the names of a member's Discord roles.
+func describeDiscordRoles(session *discordgo.Session, discordID string) string {
+	if len(discordID) == 0 {
+		return "-"
+	}
+
+	guildMember, err := session.GuildMember(discord.GuildID, discordID)
+	if err != nil {
+		return "Not in Discord"
+	}
+
+	roles, err := session.GuildRoles(discord.GuildID)
+	if err != nil {
+		return "-"
+	}
+
+	names := []string{}
+	for _, role := range roles {
+		if hasRole(guildMember, role.ID) {
+			names = append(names, role.Name)
+		}
+	}
+
+	return orNone(strings.Join(names, ", "))
+}
+
+// describeAccount summarises an account's combat level and key stats.
+func describeAccount(rsn string, result hiscores.LookupResult) string {
+	if len(rsn) == 0 {
+		return "-"
+	}
+	if errors.Is(result.Err, hiscores.ErrPlayerNotFound) {
+		return fmt.Sprintf("%s (not on the hiscores)", rsn)
+	}
+	if result.Err != nil || result.Player == nil {
+		return fmt.Sprintf("%s (hiscores unavailable)", rsn)
+	}
+
+	player := result.Player
+	stats := []string{}
+	for _, skill := range PROFILE_SKILLS {
+		s, _ := player.GetSkill(skill)
+		stats = append(stats, fmt.Sprintf("%s%s %d", strings.ToUpper(skill[:1]), skill[1:3], s.Level))
+	}
+	overall, _ := player.GetSkill("overall")
+
+	return fmt.Sprintf("**%s** · Combat %d · Total %d\n%s", rsn, player.CombatLevel(), overall.Level, strings.Join(stats, " · "))
+}
+
+// describeXpTrackerEvents lists the xp tracker events a member took part in, newest first, with their gains.
+func describeXpTrackerEvents(member memberlistentity.Member) string {
+	events, err := getStoredXpTrackerEvents()
+	if err != nil {
+		log.Printf("Failed to get xp tracker events: %v", err)
+		return "Unavailable"
+	}
+
+	events = xptracker.SortByStartDate(events)
+
+	lines := []string{}
+	participated := 0
+	for _, event := range events {
+		participant := event.GetParticipant(member.Name)
+		if participant == nil {
+			continue
+		}
+
+		participated++
+		if len(lines) >= MAXIMUM_PROFILE_EVENTS {
+			continue
+		}
+		if event.IsActive {
+			lines = append(lines, fmt.Sprintf("%s: ongoing", event.Name))
+			continue
+		}
+		line := fmt.Sprintf("%s: +%d xp", event.Name, xptracker.GetTotalXp(participant.XpGainedTable))
+		if len(event.Activities) > 0 {
+			line += fmt.Sprintf(", +%d kc", xptracker.GetTotalXp(participant.ScoreGainedTable))
+		}
+		lines = append(lines, line)
+	}
+
+	if participated == 0 {
+		return "None"
+	}
+	if participated > len(lines) {
+		lines = append(lines, fmt.Sprintf("... and %d more", participated-len(lines)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// describeAttendance counts the attendance snapshots a member appears in.
+func describeAttendance(member memberlistentity.Member) string {
+	snapshots, err := attendance.GetAttendanceSnapshots()
+	if err != nil {
+		log.Printf("Failed to get attendance snapshots: %v", err)
+		return "Unavailable"
+	}
+
+	attended := attendance.GetMemberAttendance(snapshots, member)
+	if len(attended) == 0 {
+		return fmt.Sprintf("0 of %d events", len(snapshots))
+	}
+
+	last := attended[0]
+	for _, snapshot := range attended {
+		if snapshot.Date > last.Date {
+			last = snapshot
+		}
+	}
+
+	return fmt.Sprintf("%d of %d events (last: %s, %s)", len(attended), len(snapshots), last.Name, last.Date)
+}
+
+// Execute executes ProfileCommandPlugin on an incoming Discord message.
+func (p *ProfileCommandPlugin) Execute(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	segments := strings.Split(message.Content, " ")
+
+	var member *memberlistentity.Member
+	if len(segments) < 2 {
+		member = getMemberlist().GetMemberByDiscordID(message.Author.ID)
+	} else {
+		member = getMemberlist().FindMember(strings.Join(segments[1:], " "))
+	}
+	if member == nil {
+		return MemberNotFoundError
+	}
+
+	rsns := []string{}
+	for _, account := range member.Accounts {
+		rsns = append(rsns, account.RuneScapeName)
+	}
+	results := hiscores.LookupPlayers(rsns, lookupPlayer, hiscores.DefaultPoolOptions, nil)
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Rank", Value: orNone(member.Rank), Inline: true},
+		{Name: "Discord roles", Value: describeDiscordRoles(session, member.DiscordID), Inline: true},
+	}
+	for _, account := range member.Accounts {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: accountLabel(*member, account), Value: describeAccount(account.RuneScapeName, results[account.RuneScapeName])})
+	}
+	if len(member.Accounts) == 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Accounts", Value: "None"})
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{Name: "XP tracker events", Value: describeXpTrackerEvents(*member)})
+	// No attendance is taken while the attendance plugin is disabled, so the count would always be zero.
+	if (&AttendanceCommandPlugin{}).Enabled() {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Attendance", Value: describeAttendance(*member)})
+	}
+
+	_, err := session.ChannelMessageSendEmbed(message.ChannelID, &discordgo.MessageEmbed{
+		Title:  member.Name,
+		Color:  PROFILE_COLOR,
+		Fields: fields,
+	})
+	return err
+}
@@ -0,0 +1,204 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/rankhistory"
+)
+
+const (
+	RankChangeCommandPluginName = "RankChangeCommandPlugin"
+	RankChangeReasonFlag        = "--reason"
+)
+
+var MemberNotFoundError error = errors.New("Could not find that member in the memberlist.")
+var NoDiscordIDError error = errors.New("Member has no Discord ID in the memberlist.")
+
+type RankChangeCommandPlugin struct{}
+
+// Enabled returns whether or not the RankChangeCommandPlugin is enabled.
+func (r *RankChangeCommandPlugin) Enabled() bool {
+	return true
+}
+
+// NewRankChangeCommandPlugin creates a new RankChangeCommandPlugin.
+func NewRankChangeCommandPlugin() *RankChangeCommandPlugin {
+	return &RankChangeCommandPlugin{}
+}
+
+// Name returns the name of the plugin.
+func (r *RankChangeCommandPlugin) Name() string {
+	return RankChangeCommandPluginName
+}
+
+// Validate validates whether or not we should execute RankChangeCommandPlugin on an incoming Discord message.
+func (r *RankChangeCommandPlugin) Validate(session *discordgo.Session, message *discordgo.MessageCreate) bool {
+	command := strings.Split(message.Content, " ")[0]
+	return (command == "!promote" || command == "!demote") && message.ChannelID == discord.AdminNotificationsChannelID
+}
+
+// parseRankChangeArgs splits `<member> [--reason <reason>]` into the member query and the reason.
+func parseRankChangeArgs(args []string) (string, string) {
+	for i, arg := range args {
+		if arg == RankChangeReasonFlag {
+			return strings.Join(args[:i], " "), strings.Join(args[i+1:], " ")
+		}
+	}
+
+	return strings.Join(args, " "), ""
+}
+
+// setClanRank gives a Discord member the role of a rank and removes their other clan roles.
+// If a role change fails, the member's original clan roles are restored.
+func setClanRank(session *discordgo.Session, guildMember *discordgo.Member, rank memberlistentity.Rank) error {
+	err := session.GuildMemberRoleAdd(discord.GuildID, guildMember.User.ID, rank.RoleID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range memberlistentity.RANKS {
+		if v.RoleID == rank.RoleID || !hasRole(guildMember, v.RoleID) {
+			continue
+		}
+		if err := session.GuildMemberRoleRemove(discord.GuildID, guildMember.User.ID, v.RoleID); err != nil {
+			if restoreErr := restoreClanRoles(session, guildMember, rank); restoreErr != nil {
+				log.Printf("Failed to restore clan roles of %s: %v", guildMember.User.ID, restoreErr)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreClanRoles undoes setClanRank using the roles the Discord member held before it was called.
+func restoreClanRoles(session *discordgo.Session, guildMember *discordgo.Member, rank memberlistentity.Rank) error {
+	for _, v := range memberlistentity.RANKS {
+		if !hasRole(guildMember, v.RoleID) {
+			continue
+		}
+		if err := session.GuildMemberRoleAdd(discord.GuildID, guildMember.User.ID, v.RoleID); err != nil {
+			return err
+		}
+	}
+
+	if !hasRole(guildMember, rank.RoleID) {
+		return session.GuildMemberRoleRemove(discord.GuildID, guildMember.User.ID, rank.RoleID)
+	}
+
+	return nil
+}
+
+func hasRole(guildMember *discordgo.Member, roleID string) bool {
+	for _, v := range guildMember.Roles {
+		if v == roleID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// changeRank moves a member a number of steps along the rank ladder in both Discord and the memberlist.
+// The Discord roles are rolled back if the memberlist cannot be updated.
+func (r *RankChangeCommandPlugin) changeRank(steps int, args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	query, reason := parseRankChangeArgs(args)
+	if len(query) == 0 {
+		return TooFewArgumentsError
+	}
+
+	member := getMemberlist().FindMember(query)
+	if member == nil {
+		return MemberNotFoundError
+	}
+	if len(member.DiscordID) == 0 {
+		return NoDiscordIDError
+	}
+
+	currentRank := memberlistentity.GetRankByName(member.Rank)
+	if currentRank == nil {
+		return fmt.Errorf("%s has unknown rank %q in the memberlist. Fix it before changing their rank.", member.Name, member.Rank)
+	}
+
+	newRank := memberlistentity.GetAdjacentRank(*currentRank, steps)
+	if newRank == nil {
+		return fmt.Errorf("%s is already at the end of the rank ladder (%s).", member.Name, currentRank.Name)
+	}
+
+	// Officers may only move members who are below them to ranks below them.
+	if err := requireRankAbove(message.Member, *currentRank); err != nil {
+		return err
+	}
+	if err := requireRankAbove(message.Member, *newRank); err != nil {
+		return err
+	}
+
+	guildMember, err := session.GuildMember(discord.GuildID, member.DiscordID)
+	if err != nil {
+		return err
+	}
+
+	if err := setClanRank(session, guildMember, *newRank); err != nil {
+		return err
+	}
+
+	// Only the rank is changed, and only if nobody changed it since it was read, so concurrent edits are not overwritten.
+	var updatedMember memberlistentity.Member
+	err = getMemberlist().ModifyMember(member.Key(), func(current memberlistentity.Member) (memberlistentity.Member, error) {
+		if current.Rank != currentRank.Name {
+			return current, fmt.Errorf("%s's rank changed to %s in the meantime. Try again.", current.Name, current.Rank)
+		}
+		current.Rank = newRank.Name
+		updatedMember = current
+		return current, nil
+	})
+	if errors.Is(err, memberlistentity.ErrMemberNotInMemberlist) {
+		err = MemberNotFoundError
+	}
+	if err != nil {
+		if restoreErr := restoreClanRoles(session, guildMember, *newRank); restoreErr != nil {
+			log.Printf("Failed to restore clan roles of %s: %v", member.Name, restoreErr)
+			return fmt.Errorf("Failed to update the memberlist (%v) and failed to restore Discord roles (%v). Please fix %s by hand.", err, restoreErr, member.Name)
+		}
+		return err
+	}
+
+	err = rankhistory.RecordRankChange(updatedMember, rankhistory.RankChange{
+		FromRank:    currentRank.Name,
+		ToRank:      newRank.Name,
+		ChangedByID: message.Author.ID,
+		ChangedBy:   message.Author.String(),
+		Reason:      reason,
+	})
+	if err != nil {
+		return fmt.Errorf("Changed %s from %s to %s, but failed to record rank history: %v", member.Name, currentRank.Name, newRank.Name, err)
+	}
+
+	_, err = session.ChannelMessageSendReply(message.ChannelID, fmt.Sprintf("Changed **%s** from %s to **%s**.", member.Name, currentRank.Name, newRank.Name), message.Reference())
+	return err
+}
+
+// Execute executes RankChangeCommandPlugin on an incoming Discord message.
+func (r *RankChangeCommandPlugin) Execute(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	segments := strings.Split(message.Content, " ")
+	if len(segments) < 2 {
+		return TooFewArgumentsError
+	}
+
+	if message.Member == nil || !isOfficer(message.Member) {
+		return NotOfficerError
+	}
+
+	steps := 1
+	if segments[0] == "!demote" {
+		steps = -1
+	}
+
+	return r.changeRank(steps, segments[1:], session, message)
+}
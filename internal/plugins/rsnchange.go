@@ -0,0 +1,176 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/rsnwatch"
+)
+
+const (
+	RSNChangePluginName        = "RSNChangePlugin"
+	RSNChangeCustomIDPrefix    = "rsnchange"
+	RSNChangeDetectionInterval = 12 * time.Hour
+)
+
+type RSNChangePlugin struct{}
+
+var RSNChangeOutdatedError error = errors.New("The account no longer has the RSN that vanished, so it was not updated. It may already have been changed.")
+
+var startRSNChangeDetectionJobOnce sync.Once
+
+// Enabled returns whether or not the RSNChangePlugin is enabled.
+func (r *RSNChangePlugin) Enabled() bool {
+	return true
+}
+
+// NewRSNChangePlugin creates a new RSNChangePlugin.
+func NewRSNChangePlugin() *RSNChangePlugin {
+	return &RSNChangePlugin{}
+}
+
+// Name returns the name of the plugin.
+func (r *RSNChangePlugin) Name() string {
+	return RSNChangePluginName
+}
+
+// ValidateInteraction validates whether or not we should execute RSNChangePlugin on an incoming Discord interaction.
+func (r *RSNChangePlugin) ValidateInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) bool {
+	return isInteractionFor(interaction, RSNChangeCustomIDPrefix)
+}
+
+// ExecuteInteraction accepts or dismisses a suggested RSN change.
+func (r *RSNChangePlugin) ExecuteInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	if err := requireOfficerInteraction(interaction); err != nil {
+		return err
+	}
+
+	_, action, args := parseCustomID(interactionCustomID(interaction))
+	user := interactionUser(interaction)
+
+	switch action {
+	case "accept":
+		if len(args) < 4 {
+			return TooFewArgumentsError
+		}
+		memberKey, account, oldRSN, rsn := args[0], args[1], args[2], args[3]
+		if err := updateMemberAccount(memberKey, account, oldRSN, rsn); err != nil {
+			return err
+		}
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("✅ Updated to **%s** by %s.", rsn, user.String()))
+	case "dismiss":
+		return resolveComponentMessage(session, interaction, fmt.Sprintf("Dismissed by %s.", user.String()))
+	}
+
+	return InvalidOperationError
+}
+
+// updateMemberAccount renames one of a member's accounts in the memberlist from oldRSN to rsn. The account is left alone
+// if it no longer has oldRSN, e.g. because the change was already accepted or the account was edited since.
+func updateMemberAccount(memberKey string, account string, oldRSN string, rsn string) error {
+	err := getMemberlist().ModifyMember(memberKey, func(member memberlistentity.Member) (memberlistentity.Member, error) {
+		if member.GetAccount(account) != oldRSN {
+			return member, RSNChangeOutdatedError
+		}
+		if err := member.SetAccount(account, rsn); err != nil {
+			return member, err
+		}
+		return member, nil
+	})
+	if errors.Is(err, memberlistentity.ErrMemberNotInMemberlist) {
+		return MemberNotFoundError
+	}
+
+	return err
+}
+
+// StartRSNChangeDetectionJob starts a job that periodically looks for members whose RSNs vanished from the hiscores.
+// Calling it more than once has no effect.
+func StartRSNChangeDetectionJob(session *discordgo.Session) {
+	startRSNChangeDetectionJobOnce.Do(func() {
+		go func() {
+			for {
+				detectRSNChanges(session)
+				<-time.After(RSNChangeDetectionInterval)
+			}
+		}()
+	})
+}
+
+// discordCandidateNames returns a function listing a member's memberlist and Discord names, which often follow their RSN.
+func discordCandidateNames(session *discordgo.Session) rsnwatch.CandidateNames {
+	return func(member memberlistentity.Member) []string {
+		names := []string{member.Name}
+		if len(member.DiscordID) == 0 {
+			return names
+		}
+
+		guildMember, err := session.GuildMember(discord.GuildID, member.DiscordID)
+		if err != nil || guildMember.User == nil {
+			return names
+		}
+
+		return append(names, guildMember.Nick, guildMember.User.Username)
+	}
+}
+
+// detectRSNChanges runs a single RSN change detection pass and posts the results to the admin channel.
+func detectRSNChanges(session *discordgo.Session) {
+	if err := getMemberlist().Refresh(); err != nil {
+		log.Printf("Failed to refresh memberlist, using cached copy: %v", err)
+	}
+
+	snapshots, err := rsnwatch.LoadSnapshots()
+	if err != nil {
+		log.Printf("Failed to load RSN snapshots: %v", err)
+		return
+	}
+
+	changes := rsnwatch.Detect(snapshots, getMemberlist().GetMembers(), lookupPlayer, discordCandidateNames(session))
+	if err := snapshots.Save(); err != nil {
+		log.Printf("Failed to save RSN snapshots: %v", err)
+	}
+
+	for _, change := range changes {
+		if err := postNameChange(session, change); err != nil {
+			log.Printf("Failed to post RSN change for %s: %v", change.Snapshot.MemberName, err)
+		}
+	}
+}
+
+// postNameChange posts a vanished RSN to the admin channel, with buttons to accept the suggested new name.
+func postNameChange(session *discordgo.Session, change rsnwatch.NameChange) error {
+	snapshot := change.Snapshot
+	content := fmt.Sprintf("**%s**'s %s RSN **%s** is no longer on the hiscores (last seen %s).", snapshot.MemberName, snapshot.Account, snapshot.RuneScapeName, snapshot.Date)
+	if len(change.SuggestedName) == 0 {
+		_, err := session.ChannelMessageSend(discord.AdminNotificationsChannelID, content+" No likely new name was found.")
+		return err
+	}
+
+	_, err := session.ChannelMessageSendComplex(discord.AdminNotificationsChannelID, &discordgo.MessageSend{
+		Content: content + fmt.Sprintf(" It was probably renamed to **%s**.", change.SuggestedName),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    fmt.Sprintf("Update to %s", change.SuggestedName),
+						Style:    discordgo.SuccessButton,
+						CustomID: buildCustomID(RSNChangeCustomIDPrefix, "accept", snapshot.MemberKey, snapshot.Account, snapshot.RuneScapeName, change.SuggestedName),
+					},
+					discordgo.Button{
+						Label:    "Dismiss",
+						Style:    discordgo.SecondaryButton,
+						CustomID: buildCustomID(RSNChangeCustomIDPrefix, "dismiss"),
+					},
+				},
+			},
+		},
+	})
+	return err
+}
@@ -0,0 +1,150 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+const (
+	WhoisCommandPluginName = "WhoisCommandPlugin"
+	// MAXIMUM_WHOIS_RESULTS is how many matching members !whois shows.
+	MAXIMUM_WHOIS_RESULTS = 3
+	WHOIS_COLOR           = 0x93c5fd
+)
+
+type WhoisCommandPlugin struct{}
+
+// Enabled returns whether or not the WhoisCommandPlugin is enabled.
+func (w *WhoisCommandPlugin) Enabled() bool {
+	return true
+}
+
+// NewWhoisCommandPlugin creates a new WhoisCommandPlugin.
+func NewWhoisCommandPlugin() *WhoisCommandPlugin {
+	return &WhoisCommandPlugin{}
+}
+
+// Name returns the name of the plugin.
+func (w *WhoisCommandPlugin) Name() string {
+	return WhoisCommandPluginName
+}
+
+// Validate validates whether or not we should execute WhoisCommandPlugin on an incoming Discord message.
+func (w *WhoisCommandPlugin) Validate(session *discordgo.Session, message *discordgo.MessageCreate) bool {
+	return strings.Split(message.Content, " ")[0] == "!whois"
+}
+
+// discordIdentities returns a function listing the Discord username and nickname of a member.
+func discordIdentities(guildMembers []*discordgo.Member) func(memberlistentity.Member) []memberlistentity.Identity {
+	guildMembersByID := make(map[string]*discordgo.Member)
+	for _, guildMember := range guildMembers {
+		if guildMember != nil && guildMember.User != nil {
+			guildMembersByID[guildMember.User.ID] = guildMember
+		}
+	}
+
+	return func(member memberlistentity.Member) []memberlistentity.Identity {
+		guildMember, ok := guildMembersByID[member.DiscordID]
+		if !ok {
+			return nil
+		}
+
+		return []memberlistentity.Identity{
+			{Source: memberlistentity.IdentityDiscordUsername, Value: guildMember.User.Username},
+			{Source: memberlistentity.IdentityDiscordNickname, Value: guildMember.Nick},
+		}
+	}
+}
+
+// orNone returns the value, or a placeholder if it is empty.
+func orNone(value string) string {
+	if len(value) == 0 {
+		return "-"
+	}
+
+	return value
+}
+
+// accountLabel names an account of a member, marking their primary account if they have more than one.
+func accountLabel(member memberlistentity.Member, account memberlistentity.RuneScapeAccount) string {
+	label := strings.ToUpper(account.Tag)
+	if primary := member.Accounts.Primary(); len(member.Accounts) > 1 && primary.Tag == account.Tag {
+		label += " (primary)"
+	}
+
+	return label
+}
+
+// buildWhoisEmbed renders a member and all of their linked identities.
+func buildWhoisEmbed(match memberlistentity.MemberMatch, identities []memberlistentity.Identity) *discordgo.MessageEmbed {
+	member := match.Member
+	discordValue := "-"
+	if len(member.DiscordID) > 0 {
+		discordValue = fmt.Sprintf("<@%s>", member.DiscordID)
+		for _, identity := range identities {
+			if identity.Source == memberlistentity.IdentityDiscordUsername {
+				discordValue += fmt.Sprintf(" (%s)", identity.Value)
+			}
+		}
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Rank", Value: orNone(member.Rank), Inline: true},
+		{Name: "Discord", Value: discordValue, Inline: true},
+		{Name: "TeamSpeak", Value: orNone(member.TeamSpeakID), Inline: true},
+	}
+	for _, account := range member.Accounts {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: accountLabel(member, account), Value: account.RuneScapeName, Inline: true})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:  member.Name,
+		Color:  WHOIS_COLOR,
+		Fields: fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Matched %s %q (%.0f%%) · UUID %s", match.Identity.Source, match.Identity.Value, match.Score*100, orNone(member.Uuid)),
+		},
+	}
+}
+
+// Execute executes WhoisCommandPlugin on an incoming Discord message. Only officers, or anyone in the admin notifications
+// channel, can look members up.
+func (w *WhoisCommandPlugin) Execute(session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if message.ChannelID != discord.AdminNotificationsChannelID && (message.Member == nil || !isOfficer(message.Member)) {
+		return NotOfficerError
+	}
+
+	segments := strings.Split(message.Content, " ")
+	if len(segments) < 2 {
+		return TooFewArgumentsError
+	}
+
+	query := strings.Join(segments[1:], " ")
+	if strings.HasPrefix(query, "<@") && strings.HasSuffix(query, ">") {
+		query = strings.TrimPrefix(strings.TrimSuffix(query[2:], ">"), "!")
+	}
+
+	guildMembers, err := getGuildMembers(session)
+	if err != nil {
+		return err
+	}
+
+	identities := discordIdentities(guildMembers)
+	matches := memberlistentity.SearchMembers(getMemberlist().GetMembers(), query, identities, MAXIMUM_WHOIS_RESULTS)
+	if len(matches) == 0 {
+		_, err = session.ChannelMessageSendReply(message.ChannelID, fmt.Sprintf("No members match %q.", query), message.Reference())
+		return err
+	}
+
+	embeds := []*discordgo.MessageEmbed{}
+	for _, match := range matches {
+		embeds = append(embeds, buildWhoisEmbed(match, identities(match.Member)))
+	}
+
+	_, err = session.ChannelMessageSendEmbeds(message.ChannelID, embeds)
+	return err
+}
@@ -0,0 +1,177 @@
+package plugins
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joeydotdev/corgi-discord-bot/internal/chart"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/xptracker"
+)
+
+const (
+	// XpSnapshotCheckInterval is how often active events are checked for a due interval snapshot.
+	XpSnapshotCheckInterval = time.Minute
+	// DEFAULT_CHART_PARTICIPANTS is how many of the top participants a chart shows if none are named.
+	DEFAULT_CHART_PARTICIPANTS = 5
+	// MAXIMUM_CHART_PARTICIPANTS is how many participants a chart can show.
+	MAXIMUM_CHART_PARTICIPANTS = 10
+)
+
+var ParticipantNotFoundError error = errors.New("Nobody by that name took part in the event.")
+var InvalidChartTopError error = fmt.Errorf("Charts can show the top 1 to %d participants, e.g. `top 5`.", MAXIMUM_CHART_PARTICIPANTS)
+
+// lastXpSnapshots maps the UUIDs of active events to when their last interval snapshot was taken.
+var lastXpSnapshots = make(map[string]time.Time)
+var lastXpSnapshotsMutex sync.Mutex
+var startXpSnapshotJobOnce sync.Once
+
+// isXpSnapshotDue returns whether an active event is due an interval snapshot.
+func isXpSnapshotDue(event *xptracker.XpTrackerEvent, now time.Time) bool {
+	if event.SnapshotIntervalMinutes <= 0 {
+		return false
+	}
+
+	lastXpSnapshotsMutex.Lock()
+	last, ok := lastXpSnapshots[event.Uuid]
+	lastXpSnapshotsMutex.Unlock()
+	if !ok {
+		series, err := xptracker.GetSnapshotSeries(event.Uuid)
+		if err != nil {
+			log.Printf("Failed to load snapshots of %s: %v", event.Uuid, err)
+			return false
+		}
+		last = series.LastSnapshotAt()
+		if last.IsZero() {
+			last, _ = time.Parse(time.RFC3339, event.StartDate)
+		}
+	}
+
+	return now.Sub(last) >= time.Duration(event.SnapshotIntervalMinutes)*time.Minute
+}
+
+// takeDueXpSnapshots takes an interval snapshot of every active event that is due one, and forgets when the last
+// snapshot of the events that ended was taken.
+func takeDueXpSnapshots(now time.Time) {
+	active := make(map[string]bool)
+	for _, event := range activeXpTrackerEvents.Active() {
+		active[event.Uuid] = true
+		if !isXpSnapshotDue(event, now) {
+			continue
+		}
+
+		// Events stopped meanwhile are skipped. They no longer need snapshots.
+		if err := event.RecordSnapshot(hiscores.GetPlayer, now); err != nil {
+			if err != xptracker.ErrEventNotActive {
+				log.Printf("Failed to record snapshot of %s: %v", event.Uuid, err)
+			}
+			continue
+		}
+
+		lastXpSnapshotsMutex.Lock()
+		lastXpSnapshots[event.Uuid] = now
+		lastXpSnapshotsMutex.Unlock()
+	}
+
+	lastXpSnapshotsMutex.Lock()
+	for uuid := range lastXpSnapshots {
+		if !active[uuid] {
+			delete(lastXpSnapshots, uuid)
+		}
+	}
+	lastXpSnapshotsMutex.Unlock()
+}
+
+// StartXpSnapshotJob starts a job that takes interval snapshots of active xp tracker events.
+func StartXpSnapshotJob() {
+	startXpSnapshotJobOnce.Do(func() {
+		go func() {
+			for {
+				<-time.After(XpSnapshotCheckInterval)
+				takeDueXpSnapshots(time.Now())
+			}
+		}()
+	})
+}
+
+// chartParticipants returns the participants a chart shows: the one named, or the top n with "top n".
+func chartParticipants(event *xptracker.XpTrackerEvent, series *xptracker.SnapshotSeries, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return event.TopParticipants(series, DEFAULT_CHART_PARTICIPANTS), nil
+	}
+
+	if strings.EqualFold(args[0], "top") {
+		if len(args) < 2 {
+			return nil, InvalidChartTopError
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > MAXIMUM_CHART_PARTICIPANTS {
+			return nil, InvalidChartTopError
+		}
+		return event.TopParticipants(series, n), nil
+	}
+
+	query := strings.Join(args, " ")
+	if name, ok := event.FindParticipantName(query); ok {
+		return []string{name}, nil
+	}
+	if member := getMemberlist().FindMember(query); member != nil {
+		if name, ok := event.FindParticipantName(member.Name); ok {
+			return []string{name}, nil
+		}
+	}
+
+	return nil, ParticipantNotFoundError
+}
+
+func (m *ManageXpTrackerPlugin) chart(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if len(args) < 1 {
+		return TooFewArgumentsError
+	}
+
+	event, err := resolveXpTrackerEvent(args[:1])
+	if err != nil {
+		return err
+	}
+	series, err := xptracker.GetSnapshotSeries(event.Uuid)
+	if err != nil {
+		return err
+	}
+	names, err := chartParticipants(event, series, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return xptracker.ErrNoParticipantsToChart
+	}
+
+	title := fmt.Sprintf("%s: cumulative xp gained", event.Name)
+	if len(event.GetSkills()) == 0 {
+		title = fmt.Sprintf("%s: cumulative score gained", event.Name)
+	}
+	image, err := chart.RenderLineChart(title, event.ChartSeries(series, names))
+	if err != nil {
+		return err
+	}
+
+	content := ""
+	if len(series.Snapshots) == 0 && event.SnapshotIntervalMinutes == 0 {
+		content = "This event has no interval snapshots, so only its start and end are charted. Start events with `--snapshot-every <minutes>` to chart their progress."
+	}
+
+	_, err = session.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+		Content:   content,
+		Reference: message.Reference(),
+		Files: []*discordgo.File{
+			{Name: fmt.Sprintf("%s.png", event.GetShortName()), ContentType: "image/png", Reader: bytes.NewReader(image)},
+		},
+	})
+	return err
+}
@@ -0,0 +1,216 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jessevdk/go-flags"
+	"github.com/joeydotdev/corgi-discord-bot/internal/xptracker"
+)
+
+const (
+	// XpHistoryCustomIDPrefix prefixes the custom IDs of the xp tracker history page buttons.
+	XpHistoryCustomIDPrefix = "xphistory"
+	// XP_HISTORY_PAGE_SIZE is how many events a page of the history shows.
+	XP_HISTORY_PAGE_SIZE = 10
+	// MAXIMUM_XP_HISTORY_QUERY_LENGTH keeps history page button custom IDs within Discord's 100 character limit.
+	MAXIMUM_XP_HISTORY_QUERY_LENGTH = 40
+	XP_HISTORY_COLOR                = 0x86efac
+	// XP_HISTORY_DATE_LAYOUT is the layout of the --from and --to dates.
+	XP_HISTORY_DATE_LAYOUT = "2006-01-02"
+)
+
+type xpTrackerHistoryOpts struct {
+	From string `long:"from" description:"Only list events started on or after this date, e.g. 2023-01-31"`
+	To   string `long:"to" description:"Only list events started on or before this date, e.g. 2023-03-31"`
+	Page int    `long:"page" description:"Page of the history to show" default:"1"`
+}
+
+var InvalidHistoryDateError error = errors.New("Dates must look like 2023-01-31.")
+var HistoryQueryTooLongError error = fmt.Errorf("Names to filter the history by can be at most %d characters.", MAXIMUM_XP_HISTORY_QUERY_LENGTH)
+
+// xpHistoryQuery is the filter and page of a history listing. It is carried in the custom IDs of the page buttons.
+type xpHistoryQuery struct {
+	Name string
+	From string
+	To   string
+	Page int
+}
+
+// filter returns the history filter of the query. The to date is inclusive.
+func (q xpHistoryQuery) filter() (xptracker.HistoryFilter, error) {
+	filter := xptracker.HistoryFilter{Name: q.Name}
+	if len(q.From) > 0 {
+		from, err := time.Parse(XP_HISTORY_DATE_LAYOUT, q.From)
+		if err != nil {
+			return filter, InvalidHistoryDateError
+		}
+		filter.From = from
+	}
+	if len(q.To) > 0 {
+		to, err := time.Parse(XP_HISTORY_DATE_LAYOUT, q.To)
+		if err != nil {
+			return filter, InvalidHistoryDateError
+		}
+		filter.To = to.Add(24*time.Hour - time.Second)
+	}
+
+	return filter, nil
+}
+
+// customID returns the custom ID of a button showing a page of the query.
+func (q xpHistoryQuery) customID(page int) string {
+	return buildCustomID(XpHistoryCustomIDPrefix, "page", strconv.Itoa(page), q.From, q.To, q.Name)
+}
+
+// parseXpHistoryCustomID parses the query carried by a history page button.
+func parseXpHistoryCustomID(args []string) (xpHistoryQuery, error) {
+	if len(args) < 4 {
+		return xpHistoryQuery{}, TooFewArgumentsError
+	}
+
+	page, err := strconv.Atoi(args[0])
+	if err != nil {
+		return xpHistoryQuery{}, err
+	}
+
+	return xpHistoryQuery{Page: page, From: args[1], To: args[2], Name: strings.Join(args[3:], CustomIDSeparator)}, nil
+}
+
+// describeHistoryEvent summarises an ended event on a single line.
+func describeHistoryEvent(event *xptracker.XpTrackerEvent) string {
+	dates := fmt.Sprintf("%s to %s", formatEventDate(event.StartDate), formatEventDate(event.EndDate))
+	winner := "no winner"
+	if event.Abandoned {
+		winner = "abandoned"
+	} else if participant := event.GetWinner(); participant != nil {
+		if len(event.GetSkills()) == 0 {
+			winner = fmt.Sprintf("won by %s (+%d kc)", participant.Name, xptracker.GetTotalXp(participant.ScoreGainedTable))
+		} else {
+			winner = fmt.Sprintf("won by %s (+%s xp)", participant.Name, formatGain(xptracker.GetTotalXp(participant.XpGainedTable)))
+		}
+	}
+
+	return fmt.Sprintf("**%s** `%s`\n%s · %d participant(s) · %s", event.Name, event.GetShortName(), dates, len(event.Participants), winner)
+}
+
+// formatEventDate formats an RFC3339 event date as a day, or returns it unchanged if it cannot be parsed.
+func formatEventDate(date string) string {
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return orNone(date)
+	}
+
+	return t.Format(XP_HISTORY_DATE_LAYOUT)
+}
+
+// buildXpHistoryMessage renders a page of the history with buttons to the neighbouring pages.
+func buildXpHistoryMessage(query xpHistoryQuery) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	filter, err := query.filter()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Every page button renders the history again, so the stored events are read from the cache.
+	events, err := getStoredXpTrackerEvents()
+	if err != nil {
+		return nil, nil, err
+	}
+	history := xptracker.FilterHistory(events, filter)
+
+	pages := (len(history) + XP_HISTORY_PAGE_SIZE - 1) / XP_HISTORY_PAGE_SIZE
+	if pages == 0 {
+		pages = 1
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Page > pages {
+		query.Page = pages
+	}
+
+	start := (query.Page - 1) * XP_HISTORY_PAGE_SIZE
+	end := start + XP_HISTORY_PAGE_SIZE
+	if end > len(history) {
+		end = len(history)
+	}
+
+	lines := []string{}
+	for _, event := range history[start:end] {
+		lines = append(lines, describeHistoryEvent(event))
+	}
+	description := strings.Join(lines, "\n\n")
+	if len(lines) == 0 {
+		description = "No past events match."
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "XP event history",
+		Description: description,
+		Color:       XP_HISTORY_COLOR,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d · %d event(s)", query.Page, pages, len(history))},
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Previous", Style: discordgo.SecondaryButton, CustomID: query.customID(query.Page - 1), Disabled: query.Page <= 1},
+			discordgo.Button{Label: "Next", Style: discordgo.SecondaryButton, CustomID: query.customID(query.Page + 1), Disabled: query.Page >= pages},
+		}},
+	}
+
+	return embed, components, nil
+}
+
+func (m *ManageXpTrackerPlugin) history(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	opts := &xpTrackerHistoryOpts{}
+	args, err := flags.ParseArgs(opts, args)
+	if err != nil {
+		return err
+	}
+
+	query := xpHistoryQuery{Name: strings.Join(args, " "), From: opts.From, To: opts.To, Page: opts.Page}
+	if len(query.Name) > MAXIMUM_XP_HISTORY_QUERY_LENGTH {
+		return HistoryQueryTooLongError
+	}
+
+	embed, components, err := buildXpHistoryMessage(query)
+	if err != nil {
+		return err
+	}
+
+	_, err = session.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	return err
+}
+
+// ValidateInteraction validates whether or not we should execute ManageXpTrackerPlugin on an incoming Discord interaction.
+func (m *ManageXpTrackerPlugin) ValidateInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) bool {
+	return isInteractionFor(interaction, XpHistoryCustomIDPrefix)
+}
+
+// ExecuteInteraction shows another page of the xp tracker history.
+func (m *ManageXpTrackerPlugin) ExecuteInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	_, _, args := parseCustomID(interactionCustomID(interaction))
+	query, err := parseXpHistoryCustomID(args)
+	if err != nil {
+		return err
+	}
+
+	embed, components, err := buildXpHistoryMessage(query)
+	if err != nil {
+		return err
+	}
+
+	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
@@ -0,0 +1,222 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jessevdk/go-flags"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/xptracker"
+)
+
+const (
+	// MAXIMUM_LEADERBOARD_ROWS is how many participants a leaderboard shows.
+	MAXIMUM_LEADERBOARD_ROWS = 20
+	// MAXIMUM_LEADERBOARD_COLUMNS is how many skill and activity columns a leaderboard shows before the total.
+	MAXIMUM_LEADERBOARD_COLUMNS = 4
+	// MINIMUM_LEADERBOARD_INTERVAL is the shortest time in minutes between live leaderboard refreshes, as every refresh
+	// looks every participant up on the hiscores.
+	MINIMUM_LEADERBOARD_INTERVAL = 5
+	LEADERBOARD_COLOR            = 0xfbbf24
+)
+
+type xpTrackerLeaderboardOpts struct {
+	Skill    string `long:"skill" description:"Skill or activity to rank participants by instead of total xp"`
+	Live     bool   `long:"live" description:"Keep the leaderboard updated until the event ends"`
+	Interval int    `long:"interval" description:"Minutes between live leaderboard refreshes" default:"10"`
+}
+
+var UntrackedSkillError error = errors.New("The event does not track that skill or activity.")
+var LeaderboardIntervalError error = fmt.Errorf("Live leaderboards can be refreshed at most every %d minutes.", MINIMUM_LEADERBOARD_INTERVAL)
+var LiveLeaderboardRunningError error = errors.New("The event already has a live leaderboard.")
+var EventEndedError error = errors.New("The event has ended, so its leaderboard will not change.")
+
+// liveLeaderboards is the set of UUIDs of events with a live leaderboard.
+var liveLeaderboards = make(map[string]bool)
+var liveLeaderboardsMutex sync.Mutex
+
+// formatGain abbreviates a gain, e.g. 12.3k or 1.25M.
+func formatGain(gain int64) string {
+	switch {
+	case gain >= 1000000 || gain <= -1000000:
+		return fmt.Sprintf("%.2fM", float64(gain)/1000000)
+	case gain >= 10000 || gain <= -10000:
+		return fmt.Sprintf("%.1fk", float64(gain)/1000)
+	default:
+		return fmt.Sprintf("%d", gain)
+	}
+}
+
+// leaderboardColumns returns the skills and activities a leaderboard shows a column for.
+func leaderboardColumns(event *xptracker.XpTrackerEvent, skill string) []string {
+	if len(skill) > 0 {
+		return []string{skill}
+	}
+
+	columns := append(append([]string{}, event.GetSkills()...), event.Activities...)
+	if len(columns) > MAXIMUM_LEADERBOARD_COLUMNS {
+		return nil
+	}
+
+	return columns
+}
+
+// abbreviateColumn shortens a skill or activity name to fit a leaderboard column.
+func abbreviateColumn(column string) string {
+	if len(column) > 8 {
+		column = column[:8]
+	}
+
+	words := strings.Fields(strings.ReplaceAll(column, "_", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// buildLeaderboardEmbed renders standings as a ranked table with a column per skill and the total gain.
+func buildLeaderboardEmbed(event *xptracker.XpTrackerEvent, standings []xptracker.Standing, skill string) *discordgo.MessageEmbed {
+	columns := leaderboardColumns(event, skill)
+	header := fmt.Sprintf("%-3s %-12s", "#", "Name")
+	for _, column := range columns {
+		header += fmt.Sprintf(" %8s", abbreviateColumn(column))
+	}
+	header += fmt.Sprintf(" %8s", "Total")
+
+	rows := []string{header}
+	failed := 0
+	for i, standing := range xptracker.RankStandings(standings, skill) {
+		if standing.Err != nil {
+			failed++
+			continue
+		}
+		if i >= MAXIMUM_LEADERBOARD_ROWS {
+			continue
+		}
+
+		name := standing.Participant.Name
+		if len(name) > 12 {
+			name = name[:12]
+		}
+		row := fmt.Sprintf("%-3d %-12s", i+1, name)
+		for _, column := range columns {
+			row += fmt.Sprintf(" %8s", formatGain(standing.Gained(column)))
+		}
+		row += fmt.Sprintf(" %8s", formatGain(standing.Gained("")))
+		rows = append(rows, row)
+	}
+
+	title := fmt.Sprintf("%s leaderboard", event.Name)
+	if len(skill) > 0 {
+		title = fmt.Sprintf("%s leaderboard: %s", event.Name, skill)
+	}
+	footer := fmt.Sprintf("Updated %s", time.Now().UTC().Format("2006-01-02 15:04 MST"))
+	if !event.IsActive {
+		footer = "Final results"
+	}
+	if failed > 0 {
+		footer += fmt.Sprintf(" · %d participant(s) could not be looked up", failed)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: fmt.Sprintf("```\n%s\n```", strings.Join(rows, "\n")),
+		Color:       LEADERBOARD_COLOR,
+		Footer:      &discordgo.MessageEmbedFooter{Text: footer},
+	}
+}
+
+// runLiveLeaderboard edits a leaderboard message every interval until the event ends, then shows the final results.
+// The event may be ended concurrently, so every refresh reads a copy of it.
+func runLiveLeaderboard(session *discordgo.Session, channelID string, messageID string, event *xptracker.XpTrackerEvent, skill string, interval time.Duration) {
+	defer func() {
+		liveLeaderboardsMutex.Lock()
+		delete(liveLeaderboards, event.Uuid)
+		liveLeaderboardsMutex.Unlock()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		current := event.Copy()
+		embed := buildLeaderboardEmbed(current, current.GetStandings(hiscores.GetPlayer, nil), skill)
+		if _, err := session.ChannelMessageEditEmbed(channelID, messageID, embed); err != nil {
+			log.Printf("Failed to refresh leaderboard of %s: %v", event.Uuid, err)
+		}
+		if !current.IsActive {
+			return
+		}
+	}
+}
+
+func (m *ManageXpTrackerPlugin) leaderboard(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	opts := &xpTrackerLeaderboardOpts{}
+	args, err := flags.ParseArgs(opts, args)
+	if err != nil {
+		return err
+	}
+
+	event, err := resolveXpTrackerEvent(args)
+	if err != nil {
+		return err
+	}
+
+	skill := strings.ToLower(opts.Skill)
+	if len(skill) > 0 && !event.IsTracking(skill) {
+		return UntrackedSkillError
+	}
+	if opts.Live && !event.IsActive {
+		return EventEndedError
+	}
+	if opts.Live && opts.Interval < MINIMUM_LEADERBOARD_INTERVAL {
+		return LeaderboardIntervalError
+	}
+	var live *xptracker.XpTrackerEvent
+	release := func() {}
+	if opts.Live {
+		if live = activeXpTrackerEvents.Get(event.Uuid); live == nil {
+			return EventEndedError
+		}
+
+		liveLeaderboardsMutex.Lock()
+		running := liveLeaderboards[event.Uuid]
+		liveLeaderboards[event.Uuid] = true
+		liveLeaderboardsMutex.Unlock()
+		if running {
+			return LiveLeaderboardRunningError
+		}
+		release = func() {
+			liveLeaderboardsMutex.Lock()
+			delete(liveLeaderboards, event.Uuid)
+			liveLeaderboardsMutex.Unlock()
+		}
+	}
+
+	var progress xptracker.Progress
+	if event.IsActive {
+		progress, err = sendProgressMessage(session, message, "Looking up the current gains of every participant...")
+		if err != nil {
+			release()
+			return err
+		}
+	}
+
+	embed := buildLeaderboardEmbed(event, event.GetStandings(hiscores.GetPlayer, progress), skill)
+	sent, err := session.ChannelMessageSendEmbed(message.ChannelID, embed)
+	if err != nil {
+		release()
+		return err
+	}
+
+	if opts.Live {
+		go runLiveLeaderboard(session, message.ChannelID, sent.ID, live, skill, time.Duration(opts.Interval)*time.Minute)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package plugins
+
+import (
+	"testing"
+)
+
+func TestAbbreviateColumn(t *testing.T) {
+	t.Parallel()
+
+	for column, expected := range map[string]string{
+		"attack":           "Attack",
+		"clue_scrolls_all": "Clue Scr",
+		"abyssal_sire":     "Abyssal",
+		"tztok_jad":        "Tztok Ja",
+	} {
+		if abbreviated := abbreviateColumn(column); abbreviated != expected {
+			t.Errorf("Expected %s to be abbreviated to %q, got %q", column, expected, abbreviated)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jessevdk/go-flags"
+	"github.com/joeydotdev/corgi-discord-bot/internal/discord"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/xptracker"
+)
+
+const (
+	// XpScheduleCheckInterval is how often scheduled xp tracker events are checked for starting or ending.
+	XpScheduleCheckInterval = time.Minute
+	// XpScheduleSaveAttempts is how many times saving the status of a scheduled event that started or ended is tried.
+	XpScheduleSaveAttempts = 3
+	// XpScheduleSaveBackoff is the delay between attempts to save the status of a scheduled event.
+	XpScheduleSaveBackoff = 5 * time.Second
+)
+
+type xpTrackerScheduleOpts struct {
+	Start      string `long:"start" description:"When the event starts, e.g. 2023-06-10T00:00" required:"true"`
+	End        string `long:"end" description:"When the event ends, e.g. 2023-06-12T00:00" required:"true"`
+	TimeZone   string `long:"tz" description:"IANA time zone the times are in, e.g. Europe/London" default:"UTC"`
+	Account    string `long:"account" description:"Tag of the members' accounts to track" default:"lpc"`
+	Skills     string `long:"skills" description:"Comma separated skills or presets (combat, skilling, all) to track"`
+	Activities string `long:"activities" description:"Comma separated activities and bosses to track, e.g. zulrah,clue_scrolls_all"`
+	Snapshots  int    `long:"snapshot-every" description:"Minutes between snapshots of every participant's gains for charts"`
+}
+
+var ScheduleNameInUseError error = errors.New("An active or scheduled event already uses that name.")
+var ScheduleNotFoundError error = errors.New("No pending scheduled event has that UUID or short name.")
+var ScheduleNotPendingError error = errors.New("That scheduled event is no longer pending.")
+
+// xpSchedulesMutex serialises changes to the stored schedules.
+var xpSchedulesMutex sync.Mutex
+var startXpScheduleJobOnce sync.Once
+
+// updateSchedules loads the stored schedules, applies a change and stores them again.
+func updateSchedules(change func(schedules []xptracker.ScheduledEvent) ([]xptracker.ScheduledEvent, error)) error {
+	xpSchedulesMutex.Lock()
+	defer xpSchedulesMutex.Unlock()
+
+	schedules, err := xptracker.LoadSchedules()
+	if err != nil {
+		return err
+	}
+	schedules, err = change(schedules)
+	if err != nil {
+		return err
+	}
+
+	return xptracker.SaveSchedules(schedules)
+}
+
+// updateSchedule applies a change to the stored schedule with the given UUID. ScheduleNotFoundError is returned if there
+// is no such schedule. If the change returns an error, nothing is saved.
+func updateSchedule(uuid string, change func(schedule *xptracker.ScheduledEvent) error) error {
+	return updateSchedules(func(schedules []xptracker.ScheduledEvent) ([]xptracker.ScheduledEvent, error) {
+		for i := range schedules {
+			if schedules[i].Uuid == uuid {
+				return schedules, change(&schedules[i])
+			}
+		}
+		return schedules, ScheduleNotFoundError
+	})
+}
+
+// saveScheduleStatus applies a change to the stored schedule with the given UUID, trying XpScheduleSaveAttempts times.
+// It is used once an event started or ended, when failing to save would start or end it again on the next check. A
+// missing schedule or an error returned by the change is not retried.
+func saveScheduleStatus(uuid string, change func(schedule *xptracker.ScheduledEvent) error) error {
+	var err error
+	for attempt := 0; attempt < XpScheduleSaveAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(XpScheduleSaveBackoff)
+		}
+		rejected := false
+		err = updateSchedule(uuid, func(schedule *xptracker.ScheduledEvent) error {
+			if err := change(schedule); err != nil {
+				rejected = true
+				return err
+			}
+			return nil
+		})
+		if err == nil || rejected || errors.Is(err, ScheduleNotFoundError) {
+			return err
+		}
+		log.Printf("Failed to save schedule %s: %v", uuid, err)
+	}
+
+	return err
+}
+
+func (m *ManageXpTrackerPlugin) schedule(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if message.Member == nil || !isOfficer(message.Member) {
+		return NotOfficerError
+	}
+
+	opts := &xpTrackerScheduleOpts{}
+	args, err := flags.ParseArgs(opts, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
+		return TooFewArgumentsError
+	}
+
+	name := strings.Join(args, " ")
+	if len(xptracker.ShortNameOf(name)) == 0 {
+		return InvalidEventNameError
+	}
+	account, err := memberlistentity.NormaliseAccountTag(opts.Account)
+	if err != nil {
+		return err
+	}
+	skills, err := xptracker.ParseSkills(opts.Skills)
+	if err != nil {
+		return err
+	}
+	activities, err := xptracker.ParseActivities(opts.Activities)
+	if err != nil {
+		return err
+	}
+	if opts.Snapshots != 0 && opts.Snapshots < xptracker.MINIMUM_SNAPSHOT_INTERVAL {
+		return xptracker.ErrSnapshotIntervalTooShort
+	}
+	location, err := time.LoadLocation(opts.TimeZone)
+	if err != nil {
+		return err
+	}
+	start, err := xptracker.ParseScheduleTime(opts.Start, location)
+	if err != nil {
+		return err
+	}
+	end, err := xptracker.ParseScheduleTime(opts.End, location)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := xptracker.NewScheduledEvent(name, account, skills, activities, start, end, message.Author.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	if activeXpTrackerEvents.Get(schedule.ShortName) != nil {
+		return ScheduleNameInUseError
+	}
+	schedule.SnapshotIntervalMinutes = opts.Snapshots
+
+	err = updateSchedules(func(schedules []xptracker.ScheduledEvent) ([]xptracker.ScheduledEvent, error) {
+		for _, v := range schedules {
+			if v.IsUpcoming() && v.ShortName == schedule.ShortName {
+				return nil, ScheduleNameInUseError
+			}
+		}
+		return append(schedules, schedule), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = session.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Scheduled event `%s` from %s to %s. Results will be posted in <#%s>.", schedule.ShortName, schedule.FormatTime(start), schedule.FormatTime(end), discord.XpTrackerResultsChannelID))
+	return err
+}
+
+func (m *ManageXpTrackerPlugin) unschedule(args []string, session *discordgo.Session, message *discordgo.MessageCreate) error {
+	if message.Member == nil || !isOfficer(message.Member) {
+		return NotOfficerError
+	}
+
+	query := strings.Join(args, " ")
+	var cancelled *xptracker.ScheduledEvent
+	err := updateSchedules(func(schedules []xptracker.ScheduledEvent) ([]xptracker.ScheduledEvent, error) {
+		for i := range schedules {
+			if schedules[i].Status == xptracker.ScheduleStatusPending && schedules[i].Matches(query) {
+				schedules[i].Status = xptracker.ScheduleStatusCancelled
+				cancelled = &schedules[i]
+				return schedules, nil
+			}
+		}
+		return nil, ScheduleNotFoundError
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = session.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Cancelled scheduled event `%s`.", cancelled.ShortName))
+	return err
+}
+
+// startScheduledEvent starts a scheduled event and announces it in the results channel.
+func startScheduledEvent(session *discordgo.Session, schedule xptracker.ScheduledEvent, now time.Time) {
+	fail := func(reason string) {
+		log.Printf("Failed to start scheduled xp tracker event %s: %s", schedule.ShortName, reason)
+		// Failures are logged by saveScheduleStatus.
+		saveScheduleStatus(schedule.Uuid, func(s *xptracker.ScheduledEvent) error {
+			s.Status = xptracker.ScheduleStatusFailed
+			s.Error = reason
+			return nil
+		})
+		session.ChannelMessageSend(discord.XpTrackerResultsChannelID, fmt.Sprintf("Scheduled event `%s` could not be started: %s", schedule.ShortName, reason))
+	}
+
+	if !now.Before(schedule.GetEnd()) {
+		fail("it was due to end before the bot could start it")
+		return
+	}
+	if activeXpTrackerEvents.Get(schedule.ShortName) != nil {
+		fail("another active event uses the same name")
+		return
+	}
+
+	members := getMemberlist().GetMembers()
+	event := xptracker.NewXpTrackerEvent(schedule.Name, members, schedule.Account, schedule.Skills, schedule.Activities, schedule.SnapshotIntervalMinutes, hiscores.GetPlayer, nil)
+	forgetStoredXpTrackerEvents()
+	if err := activeXpTrackerEvents.Add(event); err != nil {
+		event.Abandon()
+		fail(err.Error())
+		return
+	}
+
+	// The schedule only moves to started if it is still pending, e.g. not cancelled while the event was being created.
+	if err := saveScheduleStatus(schedule.Uuid, func(s *xptracker.ScheduledEvent) error {
+		if s.Status != xptracker.ScheduleStatusPending {
+			return ScheduleNotPendingError
+		}
+		s.Status = xptracker.ScheduleStatusStarted
+		s.EventUuid = event.Uuid
+		return nil
+	}); err != nil {
+		// Either the schedule is still pending and the event would run next to the one started on the next check, or
+		// the schedule no longer wants an event at all. Either way the event is abandoned.
+		if taken := activeXpTrackerEvents.Take(event.Uuid); taken != nil {
+			taken.Abandon()
+			forgetStoredXpTrackerEvents()
+		}
+		if errors.Is(err, ScheduleNotPendingError) || errors.Is(err, ScheduleNotFoundError) {
+			log.Printf("Abandoned scheduled xp tracker event %s: %v", schedule.ShortName, err)
+			return
+		}
+		session.ChannelMessageSend(discord.XpTrackerResultsChannelID, fmt.Sprintf("Scheduled event `%s` could not be started: its schedule could not be saved. It will be tried again.", schedule.ShortName))
+		return
+	}
+
+	_, err := session.ChannelMessageSend(discord.XpTrackerResultsChannelID, fmt.Sprintf("Scheduled event `%s` has started with %d participant(s) and ends %s.", event.GetShortName(), len(event.Participants), schedule.FormatTime(schedule.GetEnd())))
+	if err == nil {
+		err = sendSnapshotFailures(session, discord.XpTrackerResultsChannelID, event.Copy())
+	}
+	if err != nil {
+		log.Printf("Failed to announce scheduled event %s: %v", schedule.ShortName, err)
+	}
+}
+
+// endScheduledEvent ends a scheduled event, unless it was stopped by hand, and posts its results in the results channel.
+func endScheduledEvent(session *discordgo.Session, schedule xptracker.ScheduledEvent) {
+	event := activeXpTrackerEvents.Take(schedule.EventUuid)
+	if event != nil {
+		event.EndEvent(hiscores.GetPlayer, nil)
+		forgetStoredXpTrackerEvents()
+		recordXpGainActivity(event, event.Participants)
+	} else {
+		stored, err := xptracker.GetXpTrackerEventByUUID(schedule.EventUuid)
+		if err != nil {
+			reason := fmt.Sprintf("its event could not be loaded: %v", err)
+			log.Printf("Failed to end scheduled xp tracker event %s: %s", schedule.ShortName, reason)
+			if err := saveScheduleStatus(schedule.Uuid, func(s *xptracker.ScheduledEvent) error {
+				s.Status = xptracker.ScheduleStatusFailed
+				s.Error = reason
+				return nil
+			}); err != nil {
+				return
+			}
+			session.ChannelMessageSend(discord.XpTrackerResultsChannelID, fmt.Sprintf("Scheduled event `%s` could not be ended: %s", schedule.ShortName, reason))
+			return
+		}
+		if stored.IsActive {
+			// The event was taken out of the registry by a stop that has not finished yet. Its results are posted
+			// once it has.
+			return
+		}
+		event = stored
+	}
+
+	// Failures are logged by saveScheduleStatus. The results are posted regardless, as the event has ended.
+	saveScheduleStatus(schedule.Uuid, func(s *xptracker.ScheduledEvent) error {
+		s.Status = xptracker.ScheduleStatusEnded
+		return nil
+	})
+
+	embed := buildLeaderboardEmbed(event, event.GetStandings(hiscores.GetPlayer, nil), "")
+	if _, err := session.ChannelMessageSendComplex(discord.XpTrackerResultsChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("Scheduled event `%s` has ended. Use `!xptracker status %s` for details.", event.GetShortName(), event.Uuid),
+		Embeds:  []*discordgo.MessageEmbed{embed},
+	}); err != nil {
+		log.Printf("Failed to post results of scheduled event %s: %v", schedule.ShortName, err)
+	} else if err := sendSnapshotFailures(session, discord.XpTrackerResultsChannelID, event); err != nil {
+		log.Printf("Failed to post snapshot failures of scheduled event %s: %v", schedule.ShortName, err)
+	}
+}
+
+// runDueSchedules starts and ends the scheduled events that are due.
+func runDueSchedules(session *discordgo.Session, now time.Time) {
+	xpSchedulesMutex.Lock()
+	schedules, err := xptracker.LoadSchedules()
+	xpSchedulesMutex.Unlock()
+	if err != nil {
+		log.Printf("Failed to load xp tracker schedules: %v", err)
+		return
+	}
+
+	for _, v := range schedules {
+		switch {
+		case v.IsDueToStart(now):
+			startScheduledEvent(session, v, now)
+		case v.IsDueToEnd(now):
+			endScheduledEvent(session, v)
+		}
+	}
+}
+
+// StartXpScheduleJob starts a job that starts and ends scheduled xp tracker events.
+func StartXpScheduleJob(session *discordgo.Session) {
+	startXpScheduleJobOnce.Do(func() {
+		go func() {
+			for {
+				runDueSchedules(session, time.Now())
+				<-time.After(XpScheduleCheckInterval)
+			}
+		}()
+	})
+}
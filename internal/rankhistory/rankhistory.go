@@ -0,0 +1,69 @@
+package rankhistory
+
+import (
+	"fmt"
+	"time"
+
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+// RankChange is a single promotion or demotion of a member.
+type RankChange struct {
+	// FromRank is the rank the member held before the change.
+	FromRank string `json:"from_rank"`
+	// ToRank is the rank the member was moved to.
+	ToRank string `json:"to_rank"`
+	// ChangedByID is the Discord ID of the user who made the change.
+	ChangedByID string `json:"changed_by_id"`
+	// ChangedBy is the Discord username of the user who made the change.
+	ChangedBy string `json:"changed_by"`
+	// Reason is the reason given for the change.
+	Reason string `json:"reason"`
+	// Date is the date of the change.
+	Date string `json:"date"`
+}
+
+type RankHistory struct {
+	// MemberKey identifies the member the history belongs to.
+	MemberKey string `json:"member_key"`
+	// MemberName is the name of the member at the time of the last change.
+	MemberName string `json:"member_name"`
+	// Changes is a list of rank changes, oldest first.
+	Changes []RankChange `json:"changes"`
+}
+
+func storageKey(memberKey string) string {
+	return fmt.Sprintf("rankhistory/%s.json", memberKey)
+}
+
+// GetRankHistory returns the rank history of a member. Members without recorded changes have an empty history.
+func GetRankHistory(member memberlistentity.Member) (*RankHistory, error) {
+	key := member.Key()
+	history := &RankHistory{}
+	err := storage.DownloadJSON(storageKey(key), history)
+	if err == storage.ErrObjectNotFound {
+		return &RankHistory{MemberKey: key, MemberName: member.Name, Changes: []RankChange{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// RecordRankChange appends a rank change to a member's rank history.
+func RecordRankChange(member memberlistentity.Member, change RankChange) error {
+	history, err := GetRankHistory(member)
+	if err != nil {
+		return err
+	}
+
+	if len(change.Date) == 0 {
+		change.Date = time.Now().Format(time.RFC3339)
+	}
+
+	history.MemberName = member.Name
+	history.Changes = append(history.Changes, change)
+	return storage.UploadJSON(storageKey(history.MemberKey), history)
+}
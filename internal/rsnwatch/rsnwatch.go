@@ -0,0 +1,204 @@
+package rsnwatch
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+const (
+	// SnapshotsStorageKey is where account snapshots are stored.
+	SnapshotsStorageKey = "rsnwatch/snapshots.json"
+	// MaximumOverallXpGrowth is how much a renamed account's overall xp may have grown since its last snapshot, as a fraction.
+	MaximumOverallXpGrowth = 0.25
+	// MaximumRuneScapeNameLength is the longest RuneScape name the game allows.
+	MaximumRuneScapeNameLength = 12
+)
+
+// AccountSnapshot is the last known hiscores state of one of a member's accounts.
+type AccountSnapshot struct {
+	// MemberKey is the key of the member that owns the account.
+	MemberKey string `json:"member_key"`
+	// MemberName is the name of the member that owns the account.
+	MemberName string `json:"member_name"`
+	// Account is the tag of the account, e.g. lpc.
+	Account string `json:"account"`
+	// RuneScapeName is the RuneScape name of the account when the snapshot was taken.
+	RuneScapeName string `json:"runescape_name"`
+	// Skills are the account's skills when it was last seen on the hiscores.
+	Skills map[string]hiscores.Skill `json:"skills"`
+	// Date is the date the account was last seen on the hiscores.
+	Date string `json:"date"`
+	// Missing is whether the account has vanished from the hiscores since it was last seen.
+	Missing bool `json:"missing"`
+}
+
+// Snapshots is a map of "<member key>/<account>" to the account's snapshot.
+type Snapshots map[string]AccountSnapshot
+
+// NameChange is an account that vanished from the hiscores, with the name it was most likely renamed to.
+type NameChange struct {
+	// Snapshot is the last snapshot of the account before it vanished.
+	Snapshot AccountSnapshot
+	// SuggestedName is the name the account was likely renamed to, or empty if no match was found.
+	SuggestedName string
+}
+
+// CandidateNames returns names a member's account may have been renamed to.
+type CandidateNames func(member memberlistentity.Member) []string
+
+// LoadSnapshots loads the account snapshots from the data store. An empty set is returned if none were stored yet.
+func LoadSnapshots() (Snapshots, error) {
+	snapshots := Snapshots{}
+	err := storage.DownloadJSON(SnapshotsStorageKey, &snapshots)
+	if err == storage.ErrObjectNotFound {
+		return Snapshots{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// Save saves the account snapshots to the data store.
+func (s Snapshots) Save() error {
+	return storage.UploadJSON(SnapshotsStorageKey, s)
+}
+
+func snapshotKey(member memberlistentity.Member, account string) string {
+	return member.Key() + "/" + account
+}
+
+// IsLikelySameAccount returns whether a player on the hiscores could be the account captured in a snapshot.
+// No skill may have lost xp, and overall xp may not have grown by more than MaximumOverallXpGrowth.
+func IsLikelySameAccount(snapshot AccountSnapshot, player *hiscores.Player) bool {
+	compared := 0
+	for name, before := range snapshot.Skills {
+		if before.Xp < 0 {
+			// Unranked skills carry no information.
+			continue
+		}
+
+		after, ok := player.GetSkill(name)
+		if !ok || after.Xp < before.Xp {
+			return false
+		}
+		compared++
+	}
+
+	overallBefore := snapshot.Skills["overall"]
+	overallAfter, _ := player.GetSkill("overall")
+	if overallBefore.Xp <= 0 || float64(overallAfter.Xp) > float64(overallBefore.Xp)*(1+MaximumOverallXpGrowth) {
+		return false
+	}
+
+	return compared > 1
+}
+
+// normaliseCandidates splits display names such as "Joey | Bender Life" into possible RuneScape names.
+func normaliseCandidates(names []string, exclude string) []string {
+	seen := map[string]bool{strings.ToLower(exclude): true}
+	candidates := []string{}
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if len(name) == 0 || len(name) > MaximumRuneScapeNameLength || seen[key] {
+			return
+		}
+		seen[key] = true
+		candidates = append(candidates, name)
+	}
+
+	for _, name := range names {
+		add(name)
+		for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+			return strings.ContainsRune("|/()[]", r)
+		}) {
+			add(part)
+		}
+	}
+
+	return candidates
+}
+
+// findRenamedAccount looks up candidate names and returns the first one matching the snapshot.
+func findRenamedAccount(snapshot AccountSnapshot, candidates []string, lookup hiscores.Lookup) string {
+	for _, candidate := range candidates {
+		player, err := lookup(candidate)
+		if err != nil {
+			continue
+		}
+		if IsLikelySameAccount(snapshot, player) {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// Detect looks every member's accounts up on the hiscores, updating the snapshots, and returns the accounts that
+// vanished since the previous run. Lookups that fail because the hiscores are unavailable are skipped.
+func Detect(snapshots Snapshots, members []memberlistentity.Member, lookup hiscores.Lookup, candidateNames CandidateNames) []NameChange {
+	changes := []NameChange{}
+	for _, member := range members {
+		for _, v := range member.Accounts {
+			account, rsn := v.Tag, v.RuneScapeName
+			if len(rsn) == 0 {
+				continue
+			}
+
+			key := snapshotKey(member, account)
+			previous, seen := snapshots[key]
+			player, err := lookup(rsn)
+			if err == nil {
+				snapshots[key] = AccountSnapshot{
+					MemberKey:     member.Key(),
+					MemberName:    member.Name,
+					Account:       account,
+					RuneScapeName: rsn,
+					Skills:        player.Skills,
+					Date:          time.Now().Format(time.RFC3339),
+				}
+				continue
+			}
+
+			if !errors.Is(err, hiscores.ErrPlayerNotFound) {
+				log.Printf("Failed to look up %s: %v", rsn, err)
+				continue
+			}
+
+			sameAccount := seen && strings.EqualFold(previous.RuneScapeName, rsn)
+			if sameAccount && previous.Missing {
+				// Already reported.
+				continue
+			}
+
+			if !sameAccount {
+				// The account was never seen on the hiscores under this name.
+				snapshots[key] = AccountSnapshot{
+					MemberKey:     member.Key(),
+					MemberName:    member.Name,
+					Account:       account,
+					RuneScapeName: rsn,
+					Missing:       true,
+				}
+				continue
+			}
+
+			previous.Missing = true
+			snapshots[key] = previous
+			changes = append(changes, NameChange{
+				Snapshot:      previous,
+				SuggestedName: findRenamedAccount(previous, normaliseCandidates(candidateNames(member), rsn), lookup),
+			})
+		}
+	}
+
+	return changes
+}
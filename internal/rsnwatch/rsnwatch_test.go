@@ -0,0 +1,58 @@
+package rsnwatch
+
+import (
+	"testing"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func newPlayer(rsn string, overallXp int64, attackXp int64) *hiscores.Player {
+	return &hiscores.Player{
+		RuneScapeName: rsn,
+		Skills: map[string]hiscores.Skill{
+			"overall": {Rank: 1, Level: 100, Xp: overallXp},
+			"attack":  {Rank: 1, Level: 50, Xp: attackXp},
+		},
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	members := []memberlistentity.Member{
+		{Uuid: "1", Name: "joey", Accounts: memberlistentity.RuneScapeAccounts{{Tag: memberlistentity.AccountLPC, RuneScapeName: "bender life"}}},
+	}
+	players := map[string]*hiscores.Player{
+		"bender life": newPlayer("bender life", 1000000, 100000),
+		"bender":      newPlayer("bender", 1100000, 150000),
+		"joey":        newPlayer("joey", 5000000, 150000),
+	}
+	lookup := func(rsn string) (*hiscores.Player, error) {
+		if player, ok := players[rsn]; ok {
+			return player, nil
+		}
+		return nil, hiscores.ErrPlayerNotFound
+	}
+	candidates := func(member memberlistentity.Member) []string {
+		return []string{member.Name, "Joey | bender"}
+	}
+
+	snapshots := Snapshots{}
+	if changes := Detect(snapshots, members, lookup, candidates); len(changes) != 0 {
+		t.Fatalf("Expected no changes on the first run, got %+v", changes)
+	}
+
+	delete(players, "bender life")
+	changes := Detect(snapshots, members, lookup, candidates)
+	if len(changes) != 1 {
+		t.Fatalf("Expected a single change, got %+v", changes)
+	}
+	if changes[0].SuggestedName != "bender" {
+		t.Errorf("Expected bender to be suggested, got %q", changes[0].SuggestedName)
+	}
+
+	if changes := Detect(snapshots, members, lookup, candidates); len(changes) != 0 {
+		t.Errorf("Expected a vanished RSN to be reported once, got %+v", changes)
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 var MissingCredentialsError error = errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+var ErrObjectNotFound error = errors.New("object not found")
 
 // S3Client is the S3 client to use
 var S3Client *s3.Client
@@ -87,7 +89,7 @@ func UploadJSON(filename string, data interface{}) error {
 	return nil
 }
 
-// DownloadJSON downloads a JSON blob from S3
+// DownloadJSON downloads a JSON blob from S3. ErrObjectNotFound is returned if the blob does not exist.
 func DownloadJSON(filename string, data interface{}) error {
 	resp, err := S3Client.GetObject(context.TODO(), &s3.GetObjectInput{
 		Bucket: aws.String(BucketName),
@@ -96,9 +98,14 @@ func DownloadJSON(filename string, data interface{}) error {
 		options.Region = RegionName
 	})
 
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return ErrObjectNotFound
+	}
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 
 	err = json.NewDecoder(resp.Body).Decode(&data)
 	if err != nil {
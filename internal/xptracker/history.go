@@ -0,0 +1,68 @@
+package xptracker
+
+import (
+	"strings"
+	"time"
+)
+
+// HistoryFilter narrows down the ended events listed in the history.
+type HistoryFilter struct {
+	// Name, if set, must be part of the event's name or short name, ignoring case.
+	Name string
+	// From, if set, is the earliest start of a listed event.
+	From time.Time
+	// To, if set, is the latest start of a listed event.
+	To time.Time
+}
+
+// Matches returns whether an ended event passes the filter. Active events never do.
+func (f HistoryFilter) Matches(event *XpTrackerEvent) bool {
+	if event.IsActive {
+		return false
+	}
+
+	if name := strings.ToLower(strings.TrimSpace(f.Name)); len(name) > 0 {
+		if !strings.Contains(strings.ToLower(event.Name), name) && !strings.Contains(event.GetShortName(), ShortNameOf(name)) {
+			return false
+		}
+	}
+
+	start, _ := time.Parse(time.RFC3339, event.StartDate)
+	if !f.From.IsZero() && start.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && start.After(f.To) {
+		return false
+	}
+
+	return true
+}
+
+// FilterHistory returns the ended events passing the filter, most recently started first.
+func FilterHistory(events []*XpTrackerEvent, filter HistoryFilter) []*XpTrackerEvent {
+	history := []*XpTrackerEvent{}
+	for _, v := range events {
+		if filter.Matches(v) {
+			history = append(history, v)
+		}
+	}
+
+	return SortByStartDate(history)
+}
+
+// GetWinner returns the participant who gained the most xp, or the most score if the event tracked only activities.
+// nil is returned if nobody gained anything.
+func (x *XpTrackerEvent) GetWinner() *Participant {
+	gained := func(p Participant) int64 {
+		return x.totalGain(p.XpGainedTable, p.ScoreGainedTable)
+	}
+
+	var winner *Participant
+	for i, v := range x.Participants {
+		if gained(v) > 0 && (winner == nil || gained(v) > gained(*winner)) {
+			winner = &x.Participants[i]
+		}
+	}
+
+	return winner
+}
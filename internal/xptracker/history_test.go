@@ -0,0 +1,56 @@
+package xptracker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterHistory(t *testing.T) {
+	t.Parallel()
+
+	events := []*XpTrackerEvent{
+		{Uuid: "1", Name: "Combat Week", StartDate: "2023-01-07T10:00:00Z"},
+		{Uuid: "2", Name: "Skilling Comp", StartDate: "2023-02-04T10:00:00Z"},
+		{Uuid: "3", Name: "Combat Week 2", StartDate: "2023-03-04T10:00:00Z"},
+		{Uuid: "4", Name: "Combat Week 3", StartDate: "2023-04-01T10:00:00Z", IsActive: true},
+	}
+
+	history := FilterHistory(events, HistoryFilter{})
+	if len(history) != 3 || history[0].Uuid != "3" || history[2].Uuid != "1" {
+		t.Errorf("Expected ended events newest first, got %v", history)
+	}
+
+	history = FilterHistory(events, HistoryFilter{Name: "combat week"})
+	if len(history) != 2 {
+		t.Errorf("Expected events to be filtered by name, got %v", history)
+	}
+
+	from := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	history = FilterHistory(events, HistoryFilter{From: from, To: to})
+	if len(history) != 1 || history[0].Uuid != "2" {
+		t.Errorf("Expected events to be filtered by start date, got %v", history)
+	}
+}
+
+func TestGetWinner(t *testing.T) {
+	t.Parallel()
+
+	event := &XpTrackerEvent{Participants: []Participant{
+		{Name: "Corgi", XpGainedTable: XpTable{"attack": 100, "strength": 300}},
+		{Name: "Joey", XpGainedTable: XpTable{"attack": 350}, ScoreGainedTable: ScoreTable{"zulrah": 10}},
+	}}
+	if winner := event.GetWinner(); winner == nil || winner.Name != "Corgi" {
+		t.Errorf("Expected Corgi to win on total xp, got %v", winner)
+	}
+
+	event.Activities = []string{"zulrah"}
+	event.Skills = []string{}
+	if winner := event.GetWinner(); winner == nil || winner.Name != "Joey" {
+		t.Errorf("Expected Joey to win on score in an activity event, got %v", winner)
+	}
+
+	if winner := (&XpTrackerEvent{Participants: []Participant{{Name: "Idle"}}}).GetWinner(); winner != nil {
+		t.Errorf("Expected no winner when nobody gained, got %v", winner)
+	}
+}
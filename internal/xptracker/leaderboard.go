@@ -0,0 +1,106 @@
+package xptracker
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+)
+
+// Standing is a participant's gains at a point during an event.
+type Standing struct {
+	// Participant is the participant the standing is for.
+	Participant Participant
+	// XpGained is the xp the participant gained in the tracked skills.
+	XpGained XpTable
+	// ScoreGained is the score the participant gained in the tracked activities.
+	ScoreGained ScoreTable
+	// Total is the participant's total gain: xp, or score for events tracking only activities.
+	Total int64
+	// Err is why the participant's current gains could not be looked up.
+	Err error
+}
+
+// Gained returns the gain of the standing in a skill or activity, or the total gain if skill is empty.
+func (s Standing) Gained(skill string) int64 {
+	if len(skill) == 0 {
+		return s.Total
+	}
+	if xp, ok := s.XpGained[skill]; ok {
+		return xp
+	}
+
+	return s.ScoreGained[skill]
+}
+
+// IsTracking returns whether the event tracks a skill or activity.
+func (x *XpTrackerEvent) IsTracking(skill string) bool {
+	return contains(x.GetSkills(), skill) || contains(x.Activities, skill)
+}
+
+// totalGain returns a participant's total gain: xp, or score for events tracking only activities.
+func (x *XpTrackerEvent) totalGain(xp XpTable, scores ScoreTable) int64 {
+	if len(x.GetSkills()) == 0 {
+		return GetTotalXp(scores)
+	}
+
+	return GetTotalXp(xp)
+}
+
+// GetStandings returns the gains of every tracked participant. Active events look the participants up concurrently
+// to work out their current gains without changing the event, while ended events use the recorded gains. progress,
+// if set, is reported as lookups finish.
+func (x *XpTrackerEvent) GetStandings(lookup hiscores.Lookup, progress Progress) []Standing {
+	return x.getStandings(lookup, hiscores.DefaultPoolOptions, progress)
+}
+
+// getStandings returns the standings like GetStandings, spreading the lookups over the hiscores as opts allow.
+func (x *XpTrackerEvent) getStandings(lookup hiscores.Lookup, opts hiscores.PoolOptions, progress Progress) []Standing {
+	isTracked := func(p Participant) bool { return p.IsTracked() }
+
+	var results map[string]hiscores.LookupResult
+	if x.IsActive {
+		results = x.lookupParticipants(isTracked, lookup, opts, progress)
+	}
+
+	standings := []Standing{}
+	for _, v := range x.Participants {
+		if !isTracked(v) {
+			continue
+		}
+		if !x.IsActive && len(v.Error) > 0 {
+			standings = append(standings, Standing{Participant: v, Err: errors.New(v.Error)})
+			continue
+		}
+		if !x.IsActive {
+			standings = append(standings, Standing{Participant: v, XpGained: v.XpGainedTable, ScoreGained: v.ScoreGainedTable, Total: x.totalGain(v.XpGainedTable, v.ScoreGainedTable)})
+			continue
+		}
+
+		result := results[v.RuneScapeName]
+		if result.Err != nil {
+			standings = append(standings, Standing{Participant: v, Err: result.Err})
+			continue
+		}
+
+		xp, scores, _ := v.gains(result.Player, x.GetSkills(), x.Activities)
+		standings = append(standings, Standing{Participant: v, XpGained: xp, ScoreGained: scores, Total: x.totalGain(xp, scores)})
+	}
+
+	return standings
+}
+
+// RankStandings orders standings from the largest gain in a skill or activity, or in total if skill is empty.
+// Standings that could not be looked up come last.
+func RankStandings(standings []Standing, skill string) []Standing {
+	ranked := append([]Standing{}, standings...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if (ranked[i].Err == nil) != (ranked[j].Err == nil) {
+			return ranked[i].Err == nil
+		}
+
+		return ranked[i].Gained(skill) > ranked[j].Gained(skill)
+	})
+
+	return ranked
+}
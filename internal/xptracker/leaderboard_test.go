@@ -0,0 +1,84 @@
+package xptracker
+
+import (
+	"testing"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores/hiscorestest"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func TestStandings(t *testing.T) {
+	t.Parallel()
+
+	server := hiscorestest.NewServer()
+	defer server.Close()
+	server.SetXp("Corgi", map[string]int64{"attack": 1000, "slayer": 1000})
+	server.SetXp("Joey", map[string]int64{"attack": 1000, "slayer": 1000})
+	server.SetXp("Gone", map[string]int64{"attack": 1000, "slayer": 1000})
+
+	opts := hiscores.PoolOptions{Workers: 2}
+
+	members := []memberlistentity.Member{}
+	for _, name := range []string{"Corgi", "Joey", "Gone"} {
+		members = append(members, memberlistentity.Member{Name: name, Accounts: memberlistentity.RuneScapeAccounts{{Tag: "lpc", RuneScapeName: name}}})
+	}
+	event := newXpTrackerEvent("Test", members, "lpc", []string{"attack", "slayer"}, nil, server.Lookup(), opts, nil)
+
+	server.SetXp("Corgi", map[string]int64{"attack": 5000, "slayer": 1100})
+	server.SetXp("Joey", map[string]int64{"attack": 1500, "slayer": 9000})
+	server.SetUnavailable("Gone")
+
+	standings := event.getStandings(server.Lookup(), opts, nil)
+	if len(standings) != 3 {
+		t.Fatalf("Expected a standing per participant, got %v", standings)
+	}
+	if event.GetParticipant("Corgi").XpGainedTable != nil {
+		t.Errorf("Expected standings of an active event not to record gains")
+	}
+
+	ranked := RankStandings(standings, "")
+	if ranked[0].Participant.Name != "Joey" || ranked[0].Gained("") != 8500 || ranked[2].Err == nil {
+		t.Errorf("Unexpected total ranking %+v", ranked)
+	}
+	ranked = RankStandings(standings, "attack")
+	if ranked[0].Participant.Name != "Corgi" || ranked[0].Gained("attack") != 4000 {
+		t.Errorf("Unexpected attack ranking %+v", ranked)
+	}
+	if !event.IsTracking("slayer") || event.IsTracking("magic") {
+		t.Errorf("Expected event to track only attack and slayer")
+	}
+}
+
+func TestStandingsOfActivityOnlyEventsTotalScore(t *testing.T) {
+	t.Parallel()
+
+	event := &XpTrackerEvent{Activities: []string{"zulrah"}, Participants: []Participant{
+		{Name: "Corgi", InitialXpTable: XpTable{}, ScoreGainedTable: ScoreTable{"zulrah": 20}},
+		{Name: "Joey", InitialXpTable: XpTable{}, ScoreGainedTable: ScoreTable{"zulrah": 35}},
+	}}
+
+	ranked := RankStandings(event.GetStandings(nil, nil), "")
+	if ranked[0].Participant.Name != "Joey" || ranked[0].Gained("") != 35 || ranked[1].Gained("") != 20 {
+		t.Errorf("Expected activity only events to be ranked by total score, got %+v", ranked)
+	}
+}
+
+func TestCopyIsIndependentOfTheEvent(t *testing.T) {
+	t.Parallel()
+
+	event := &XpTrackerEvent{IsActive: true, Skills: []string{"attack"}, Participants: []Participant{
+		{Name: "Corgi", InitialXpTable: XpTable{"attack": 100}},
+		{Name: "Joey", Error: "player is not on the hiscores"},
+	}}
+	copied := event.Copy()
+
+	event.IsActive = false
+	event.Participants[0].InitialXpTable["attack"] = 200
+	if !copied.IsActive || copied.Participants[0].InitialXpTable["attack"] != 100 {
+		t.Errorf("Expected the copy not to change with the event, got %+v", copied)
+	}
+	if copied.Participants[1].IsTracked() {
+		t.Errorf("Expected the copy to keep untracked participants untracked")
+	}
+}
@@ -0,0 +1,133 @@
+package xptracker
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+var ErrShortNameInUse error = errors.New("an active event already uses that short name")
+
+// ShortNameOf derives the short name of an event from its name, e.g. "Combat Week" becomes "combat-week".
+func ShortNameOf(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "-")
+}
+
+// GetShortName returns the short name the event can be addressed by. Events stored before short names existed use
+// the short name derived from their name.
+func (x *XpTrackerEvent) GetShortName() string {
+	if len(x.ShortName) > 0 {
+		return x.ShortName
+	}
+
+	return ShortNameOf(x.Name)
+}
+
+// Matches returns whether the event is addressed by a UUID or short name, ignoring case.
+func (x *XpTrackerEvent) Matches(query string) bool {
+	query = strings.TrimSpace(query)
+	if len(query) == 0 {
+		return false
+	}
+
+	return strings.EqualFold(x.Uuid, query) || strings.EqualFold(x.GetShortName(), ShortNameOf(query))
+}
+
+// Registry holds the events that are currently active. Events are taken out of the registry before they are ended, so
+// that only one caller ends them. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	events map[string]*XpTrackerEvent
+}
+
+// NewRegistry creates a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{events: make(map[string]*XpTrackerEvent)}
+}
+
+// Add adds an active event to the registry. ErrShortNameInUse is returned if another active event has the same short
+// name.
+func (r *Registry) Add(event *XpTrackerEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range r.events {
+		if v.Uuid != event.Uuid && v.GetShortName() == event.GetShortName() {
+			return ErrShortNameInUse
+		}
+	}
+	r.events[event.Uuid] = event
+
+	return nil
+}
+
+// Get returns the active event addressed by a UUID or short name, or nil if there is none.
+func (r *Registry) Get(query string) *XpTrackerEvent {
+	for _, v := range r.Active() {
+		if v.Matches(query) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// Active returns the active events, most recently started first.
+func (r *Registry) Active() []*XpTrackerEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []*XpTrackerEvent{}
+	for _, v := range r.events {
+		events = append(events, v)
+	}
+
+	return SortByStartDate(events)
+}
+
+// Take removes the active event addressed by a UUID or short name from the registry and returns it, or nil if there is
+// none. Only the caller taking an event may end or abandon it.
+func (r *Registry) Take(query string) *XpTrackerEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for uuid, v := range r.events {
+		if v.Matches(query) {
+			delete(r.events, uuid)
+			return v
+		}
+	}
+
+	return nil
+}
+
+// SortByStartDate returns the events ordered from the most recently started.
+func SortByStartDate(events []*XpTrackerEvent) []*XpTrackerEvent {
+	sorted := append([]*XpTrackerEvent{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _ := time.Parse(time.RFC3339, sorted[i].StartDate)
+		b, _ := time.Parse(time.RFC3339, sorted[j].StartDate)
+		return a.After(b)
+	})
+
+	return sorted
+}
+
+// filterActive returns the active events, most recently started first.
+func filterActive(events []*XpTrackerEvent) []*XpTrackerEvent {
+	active := []*XpTrackerEvent{}
+	for _, v := range events {
+		if v.IsActive {
+			active = append(active, v)
+		}
+	}
+
+	return SortByStartDate(active)
+}
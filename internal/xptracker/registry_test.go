@@ -0,0 +1,60 @@
+package xptracker
+
+import (
+	"testing"
+)
+
+func TestShortNameOf(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]string{
+		"Combat Week":          "combat-week",
+		"  Skilling -- Comp! ": "skilling-comp",
+		"Zulrah 2023":          "zulrah-2023",
+		"!!!":                  "",
+	} {
+		if shortName := ShortNameOf(name); shortName != expected {
+			t.Errorf("Expected short name of %q to be %q, got %q", name, expected, shortName)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	combat := &XpTrackerEvent{Uuid: "1", Name: "Combat Week", ShortName: "combat-week", IsActive: true, StartDate: "2023-01-01T10:00:00Z"}
+	skilling := &XpTrackerEvent{Uuid: "2", Name: "Skilling Comp", IsActive: true, StartDate: "2023-01-02T10:00:00Z"}
+	if err := registry.Add(combat); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(skilling); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(&XpTrackerEvent{Uuid: "3", Name: "combat week", IsActive: true}); err != ErrShortNameInUse {
+		t.Errorf("Expected short name clash to be refused, got %v", err)
+	}
+
+	if registry.Get("Combat Week") != combat || registry.Get("skilling-comp") != skilling || registry.Get("1") != combat {
+		t.Errorf("Expected events to be addressed by UUID and short name")
+	}
+	if registry.Get("") != nil || registry.Get("bossing") != nil {
+		t.Errorf("Expected unknown events not to be found")
+	}
+	if active := registry.Active(); len(active) != 2 || active[0] != skilling {
+		t.Errorf("Expected active events newest first, got %v", active)
+	}
+
+	if taken := registry.Take("combat-week"); taken != combat {
+		t.Errorf("Expected the event to be taken, got %v", taken)
+	}
+	if taken := registry.Take("1"); taken != nil {
+		t.Errorf("Expected an event to be taken only once, got %v", taken)
+	}
+	if active := registry.Active(); len(active) != 1 || active[0] != skilling {
+		t.Errorf("Expected taken events to be removed, got %v", active)
+	}
+	if err := registry.Add(&XpTrackerEvent{Uuid: "4", Name: "Combat Week", IsActive: true}); err != nil {
+		t.Errorf("Expected short name of a taken event to be reusable, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package xptracker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+// SchedulesStorageKey is where scheduled events are stored. It is outside xptracker/ so schedules are not mistaken
+// for events.
+const SchedulesStorageKey = "xpschedules/schedules.json"
+
+// Statuses of a scheduled event.
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusStarted   = "started"
+	ScheduleStatusEnded     = "ended"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusFailed    = "failed"
+)
+
+// SCHEDULE_TIME_LAYOUTS are the layouts accepted for the start and end of scheduled events, in the order tried.
+var SCHEDULE_TIME_LAYOUTS []string = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+var ErrInvalidScheduleTime error = errors.New("times must look like 2006-01-02T15:04 or 2006-01-02")
+var ErrScheduleEndsBeforeStart error = errors.New("a scheduled event must end after it starts")
+var ErrScheduleInPast error = errors.New("a scheduled event must start in the future")
+
+// ScheduledEvent is an event that starts and ends automatically.
+type ScheduledEvent struct {
+	// Uuid is the uuid of the schedule.
+	Uuid string `json:"uuid"`
+	// Name is the name the event will be started with.
+	Name string `json:"name"`
+	// ShortName is the name the event and its schedule can be addressed by.
+	ShortName string `json:"short_name"`
+	// Account is the tag of the members' accounts to track.
+	Account string `json:"account"`
+	// Skills are the skills to track.
+	Skills []string `json:"skills,omitempty"`
+	// Activities are the activities and bosses to track.
+	Activities []string `json:"activities,omitempty"`
+	// StartAt is when the event starts, in RFC3339.
+	StartAt string `json:"start_at"`
+	// EndAt is when the event ends, in RFC3339.
+	EndAt string `json:"end_at"`
+	// TimeZone is the IANA time zone the times were given in.
+	TimeZone string `json:"time_zone"`
+	// SnapshotIntervalMinutes is how many minutes apart interval snapshots of the event are taken.
+	SnapshotIntervalMinutes int `json:"snapshot_interval_minutes,omitempty"`
+	// ScheduledBy is the Discord ID of the user who scheduled the event.
+	ScheduledBy string `json:"scheduled_by"`
+	// Status is the status of the schedule, e.g. pending.
+	Status string `json:"status"`
+	// EventUuid is the uuid of the event once it has started.
+	EventUuid string `json:"event_uuid,omitempty"`
+	// Error is why the event could not be started or ended.
+	Error string `json:"error,omitempty"`
+}
+
+// ParseScheduleTime parses the start or end of a scheduled event in a time zone.
+func ParseScheduleTime(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range SCHEDULE_TIME_LAYOUTS {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), location); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w, got %q", ErrInvalidScheduleTime, value)
+}
+
+// NewScheduledEvent creates a new pending schedule for an event running from start to end.
+func NewScheduledEvent(name string, account string, skills []string, activities []string, start time.Time, end time.Time, scheduledBy string, now time.Time) (ScheduledEvent, error) {
+	if !start.After(now) {
+		return ScheduledEvent{}, ErrScheduleInPast
+	}
+	if !end.After(start) {
+		return ScheduledEvent{}, ErrScheduleEndsBeforeStart
+	}
+
+	return ScheduledEvent{
+		Uuid:        uuid.New().String(),
+		Name:        name,
+		ShortName:   ShortNameOf(name),
+		Account:     account,
+		Skills:      skills,
+		Activities:  activities,
+		StartAt:     start.UTC().Format(time.RFC3339),
+		EndAt:       end.UTC().Format(time.RFC3339),
+		TimeZone:    start.Location().String(),
+		ScheduledBy: scheduledBy,
+		Status:      ScheduleStatusPending,
+	}, nil
+}
+
+// GetStart returns when the event starts.
+func (s ScheduledEvent) GetStart() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.StartAt)
+	return t
+}
+
+// GetEnd returns when the event ends.
+func (s ScheduledEvent) GetEnd() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.EndAt)
+	return t
+}
+
+// FormatTime formats a time in the time zone the event was scheduled in.
+func (s ScheduledEvent) FormatTime(t time.Time) string {
+	if location, err := time.LoadLocation(s.TimeZone); err == nil {
+		t = t.In(location)
+	}
+
+	return t.Format("2006-01-02 15:04 MST")
+}
+
+// IsUpcoming returns whether the event has been scheduled but not started or ended yet.
+func (s ScheduledEvent) IsUpcoming() bool {
+	return s.Status == ScheduleStatusPending || s.Status == ScheduleStatusStarted
+}
+
+// Matches returns whether the schedule is addressed by a UUID or short name, ignoring case.
+func (s ScheduledEvent) Matches(query string) bool {
+	query = strings.TrimSpace(query)
+	if len(query) == 0 {
+		return false
+	}
+
+	return strings.EqualFold(s.Uuid, query) || strings.EqualFold(s.ShortName, ShortNameOf(query))
+}
+
+// IsDueToStart returns whether a pending event should have started by now.
+func (s ScheduledEvent) IsDueToStart(now time.Time) bool {
+	return s.Status == ScheduleStatusPending && !now.Before(s.GetStart())
+}
+
+// IsDueToEnd returns whether a started event should have ended by now.
+func (s ScheduledEvent) IsDueToEnd(now time.Time) bool {
+	return s.Status == ScheduleStatusStarted && !now.Before(s.GetEnd())
+}
+
+// LoadSchedules returns every stored schedule.
+func LoadSchedules() ([]ScheduledEvent, error) {
+	schedules := []ScheduledEvent{}
+	err := storage.DownloadJSON(SchedulesStorageKey, &schedules)
+	if err != nil && err != storage.ErrObjectNotFound {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// SaveSchedules stores the schedules.
+func SaveSchedules(schedules []ScheduledEvent) error {
+	return storage.UploadJSON(SchedulesStorageKey, schedules)
+}
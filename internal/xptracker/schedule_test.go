@@ -0,0 +1,62 @@
+package xptracker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseScheduleTime(t *testing.T) {
+	t.Parallel()
+
+	location, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+
+	for value, expected := range map[string]string{
+		"2023-06-10T00:00":          "2023-06-09T23:00:00Z",
+		"2023-01-10":                "2023-01-10T00:00:00Z",
+		"2023-06-10T12:30:00-04:00": "2023-06-10T16:30:00Z",
+	} {
+		parsed, err := ParseScheduleTime(value, location)
+		if err != nil || parsed.UTC().Format(time.RFC3339) != expected {
+			t.Errorf("Expected %q to parse as %s, got %s (%v)", value, expected, parsed.UTC().Format(time.RFC3339), err)
+		}
+	}
+
+	if _, err := ParseScheduleTime("saturday", location); !errors.Is(err, ErrInvalidScheduleTime) {
+		t.Errorf("Expected invalid time to be refused, got %v", err)
+	}
+}
+
+func TestScheduledEvent(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	start := now.Add(24 * time.Hour)
+	end := start.Add(48 * time.Hour)
+
+	if _, err := NewScheduledEvent("Weekend", "lpc", nil, nil, now.Add(-time.Minute), end, "1", now); err != ErrScheduleInPast {
+		t.Errorf("Expected schedule in the past to be refused, got %v", err)
+	}
+	if _, err := NewScheduledEvent("Weekend", "lpc", nil, nil, start, start, "1", now); err != ErrScheduleEndsBeforeStart {
+		t.Errorf("Expected schedule ending at its start to be refused, got %v", err)
+	}
+
+	schedule, err := NewScheduledEvent("Weekend Comp", "lpc", nil, nil, start, end, "1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schedule.Matches("weekend comp") || !schedule.Matches(schedule.Uuid) {
+		t.Errorf("Expected schedule to be addressed by short name and UUID")
+	}
+	if schedule.IsDueToStart(now) || !schedule.IsDueToStart(start) {
+		t.Errorf("Expected schedule to be due to start at its start")
+	}
+
+	schedule.Status = ScheduleStatusStarted
+	if schedule.IsDueToEnd(start) || !schedule.IsDueToEnd(end.Add(time.Minute)) {
+		t.Errorf("Expected schedule to be due to end at its end")
+	}
+}
@@ -0,0 +1,177 @@
+package xptracker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/chart"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
+)
+
+// MINIMUM_SNAPSHOT_INTERVAL is the shortest time in minutes between interval snapshots, as every snapshot looks
+// every participant up on the hiscores.
+const MINIMUM_SNAPSHOT_INTERVAL = 30
+
+var ErrSnapshotIntervalTooShort error = fmt.Errorf("snapshots can be taken at most every %d minutes", MINIMUM_SNAPSHOT_INTERVAL)
+var ErrNoParticipantsToChart error = errors.New("no participants to chart")
+
+// Snapshot is the gains of every participant at a point during an event.
+type Snapshot struct {
+	// Date is when the snapshot was taken, in RFC3339.
+	Date string `json:"date"`
+	// Gains maps participant names to their total gain since the start of the event: xp, or score for events tracking
+	// only activities.
+	Gains map[string]int64 `json:"gains"`
+}
+
+// SnapshotSeries is the interval snapshots of an event.
+type SnapshotSeries struct {
+	// EventUuid is the uuid of the event.
+	EventUuid string `json:"event_uuid"`
+	// Snapshots are the snapshots of the event, oldest first.
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// snapshotsKey returns where the snapshots of an event are stored. They are outside xptracker/ so they are not mistaken
+// for events.
+func snapshotsKey(uuid string) string {
+	return fmt.Sprintf("xpsnapshots/%s.json", uuid)
+}
+
+// GetSnapshotSeries returns the stored snapshots of an event. Events without snapshots have an empty series.
+func GetSnapshotSeries(uuid string) (*SnapshotSeries, error) {
+	series := &SnapshotSeries{EventUuid: uuid, Snapshots: []Snapshot{}}
+	err := storage.DownloadJSON(snapshotsKey(uuid), series)
+	if err != nil && err != storage.ErrObjectNotFound {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// Save stores the snapshots.
+func (s *SnapshotSeries) Save() error {
+	return storage.UploadJSON(snapshotsKey(s.EventUuid), s)
+}
+
+// LastSnapshotAt returns when the last snapshot was taken, or the zero time if there is none.
+func (s *SnapshotSeries) LastSnapshotAt() time.Time {
+	if len(s.Snapshots) == 0 {
+		return time.Time{}
+	}
+
+	t, _ := time.Parse(time.RFC3339, s.Snapshots[len(s.Snapshots)-1].Date)
+	return t
+}
+
+// TakeSnapshot looks every tracked participant up and returns their current gains. Participants that could not be
+// looked up are left out. The snapshot is taken of a copy of the event, as it may be ended meanwhile.
+func (x *XpTrackerEvent) TakeSnapshot(lookup hiscores.Lookup, now time.Time) Snapshot {
+	return x.Copy().takeSnapshot(lookup, hiscores.DefaultPoolOptions, now)
+}
+
+// takeSnapshot takes a snapshot like TakeSnapshot, spreading the lookups over the hiscores as opts allow.
+func (x *XpTrackerEvent) takeSnapshot(lookup hiscores.Lookup, opts hiscores.PoolOptions, now time.Time) Snapshot {
+	snapshot := Snapshot{Date: now.Format(time.RFC3339), Gains: make(map[string]int64)}
+	for _, v := range x.getStandings(lookup, opts, nil) {
+		if v.Err == nil {
+			snapshot.Gains[v.Participant.Name] = v.Total
+		}
+	}
+
+	return snapshot
+}
+
+// RecordSnapshot takes a snapshot of the event and stores it with the event's other snapshots. ErrEventNotActive is
+// returned if the event has ended.
+func (x *XpTrackerEvent) RecordSnapshot(lookup hiscores.Lookup, now time.Time) error {
+	current := x.Copy()
+	if !current.IsActive {
+		return ErrEventNotActive
+	}
+
+	series, err := GetSnapshotSeries(current.Uuid)
+	if err != nil {
+		return err
+	}
+
+	series.Snapshots = append(series.Snapshots, current.takeSnapshot(lookup, hiscores.DefaultPoolOptions, now))
+	return series.Save()
+}
+
+// ChartSeries returns the cumulative gains of the named participants over the course of the event, starting from
+// zero at the start of the event. Ended events finish with the recorded gains.
+func (x *XpTrackerEvent) ChartSeries(series *SnapshotSeries, names []string) []chart.Series {
+	start, _ := time.Parse(time.RFC3339, x.StartDate)
+	lines := []chart.Series{}
+	for _, name := range names {
+		points := []chart.Point{{Time: start, Value: 0}}
+		for _, snapshot := range series.Snapshots {
+			gain, ok := snapshot.Gains[name]
+			if !ok {
+				continue
+			}
+			date, err := time.Parse(time.RFC3339, snapshot.Date)
+			if err != nil {
+				continue
+			}
+			points = append(points, chart.Point{Time: date, Value: gain})
+		}
+
+		participant := x.GetParticipant(name)
+		if end, err := time.Parse(time.RFC3339, x.EndDate); err == nil && !x.IsActive && !x.Abandoned && participant != nil {
+			points = append(points, chart.Point{Time: end, Value: x.totalGain(participant.XpGainedTable, participant.ScoreGainedTable)})
+		}
+
+		lines = append(lines, chart.Series{Name: name, Points: points})
+	}
+
+	return lines
+}
+
+// TopParticipants returns the names of up to n participants with the largest latest gain, from the recorded gains of
+// ended events or the last snapshot of active ones.
+func (x *XpTrackerEvent) TopParticipants(series *SnapshotSeries, n int) []string {
+	latest := make(map[string]int64)
+	if len(series.Snapshots) > 0 {
+		latest = series.Snapshots[len(series.Snapshots)-1].Gains
+	}
+
+	gains := make(map[string]int64)
+	names := []string{}
+	for _, v := range x.Participants {
+		if !v.IsTracked() {
+			continue
+		}
+		gain := latest[v.Name]
+		if !x.IsActive {
+			gain = x.totalGain(v.XpGainedTable, v.ScoreGainedTable)
+		}
+		gains[v.Name] = gain
+		names = append(names, v.Name)
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		return gains[names[i]] > gains[names[j]]
+	})
+	if len(names) > n {
+		names = names[:n]
+	}
+
+	return names
+}
+
+// FindParticipantName returns the name of the participant matching a name or RSN, ignoring case.
+func (x *XpTrackerEvent) FindParticipantName(query string) (string, bool) {
+	for _, v := range x.Participants {
+		if strings.EqualFold(v.Name, query) || strings.EqualFold(v.RuneScapeName, query) {
+			return v.Name, true
+		}
+	}
+
+	return "", false
+}
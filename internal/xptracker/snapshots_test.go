@@ -0,0 +1,82 @@
+package xptracker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores/hiscorestest"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	t.Parallel()
+
+	server := hiscorestest.NewServer()
+	defer server.Close()
+	server.SetXp("Corgi", map[string]int64{"attack": 1500})
+	server.SetUnavailable("Gone")
+
+	opts := hiscores.PoolOptions{Workers: 2}
+
+	event := &XpTrackerEvent{IsActive: true, Skills: []string{"attack"}, Participants: []Participant{
+		{Name: "Corgi", RuneScapeName: "Corgi", InitialXpTable: XpTable{"attack": 1000}},
+		{Name: "Gone", RuneScapeName: "Gone", InitialXpTable: XpTable{"attack": 1000}},
+	}}
+
+	now := time.Date(2023, 6, 10, 12, 0, 0, 0, time.UTC)
+	snapshot := event.takeSnapshot(server.Lookup(), opts, now)
+	if snapshot.Date != "2023-06-10T12:00:00Z" || !reflect.DeepEqual(snapshot.Gains, map[string]int64{"Corgi": 500}) {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+}
+
+func TestRecordSnapshotSkipsEndedEvents(t *testing.T) {
+	t.Parallel()
+
+	event := &XpTrackerEvent{Uuid: "1", Skills: []string{"attack"}}
+	if err := event.RecordSnapshot(nil, time.Now()); err != ErrEventNotActive {
+		t.Errorf("Expected no snapshot of an ended event to be recorded, got %v", err)
+	}
+	if key := snapshotsKey("1"); strings.HasPrefix(key, "xptracker/") {
+		t.Errorf("Expected snapshots to be stored apart from events, got %s", key)
+	}
+}
+
+func TestChartSeries(t *testing.T) {
+	t.Parallel()
+
+	event := &XpTrackerEvent{
+		StartDate: "2023-06-10T00:00:00Z",
+		EndDate:   "2023-06-10T03:00:00Z",
+		Participants: []Participant{
+			{Name: "Corgi", XpGainedTable: XpTable{"attack": 900}},
+			{Name: "Joey", XpGainedTable: XpTable{"attack": 1200}},
+			{Name: "Failed", Error: "player is not on the hiscores"},
+		},
+	}
+	series := &SnapshotSeries{Snapshots: []Snapshot{
+		{Date: "2023-06-10T01:00:00Z", Gains: map[string]int64{"Corgi": 300, "Joey": 100}},
+		{Date: "2023-06-10T02:00:00Z", Gains: map[string]int64{"Corgi": 600}},
+	}}
+
+	lines := event.ChartSeries(series, []string{"Corgi", "Joey"})
+	if len(lines) != 2 || len(lines[0].Points) != 4 || len(lines[1].Points) != 3 {
+		t.Fatalf("Unexpected chart series %+v", lines)
+	}
+	if lines[0].Points[0].Value != 0 || lines[0].Points[3].Value != 900 {
+		t.Errorf("Expected series to run from zero to the recorded gain, got %+v", lines[0].Points)
+	}
+
+	if top := event.TopParticipants(series, 1); !reflect.DeepEqual(top, []string{"Joey"}) {
+		t.Errorf("Expected Joey to top the ended event, got %v", top)
+	}
+	event.IsActive = true
+	if top := event.TopParticipants(series, 5); !reflect.DeepEqual(top, []string{"Corgi", "Joey"}) {
+		t.Errorf("Expected the last snapshot to rank an active event, got %v", top)
+	}
+	if name, ok := event.FindParticipantName("joey"); !ok || name != "Joey" {
+		t.Errorf("Expected participant to be found ignoring case")
+	}
+}
@@ -0,0 +1,128 @@
+package xptracker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+)
+
+var ErrUnknownSkill error = errors.New("unknown skill")
+var ErrUnknownActivity error = errors.New("unknown activity")
+
+// COMBAT_SKILLS are the skills tracked by events that do not name any.
+var COMBAT_SKILLS []string = []string{"attack", "strength", "defence", "ranged", "magic", "hitpoints"}
+
+// SKILLING_SKILLS are the non-combat skills.
+var SKILLING_SKILLS []string = []string{"cooking", "woodcutting", "fletching", "fishing", "firemaking", "crafting", "smithing", "mining", "herblore", "agility", "thieving", "slayer", "farming", "runecraft", "hunter", "construction"}
+
+// SKILL_PRESETS maps preset names that may be given in place of skills to the skills they stand for.
+var SKILL_PRESETS map[string][]string = map[string][]string{
+	"combat":   COMBAT_SKILLS,
+	"skilling": SKILLING_SKILLS,
+	"all":      hiscores.SKILLS[1:],
+}
+
+// splitList splits a comma separated list, lowercasing and dropping empty entries.
+func splitList(value string) []string {
+	entries := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); len(v) > 0 {
+			entries = append(entries, v)
+		}
+	}
+
+	return entries
+}
+
+// appendUnique appends the values to a list, skipping those it already contains.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !contains(list, v) {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+// contains returns whether a list contains a value.
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseSkills parses a comma separated list of skills and skill presets, e.g. "combat,prayer".
+func ParseSkills(value string) ([]string, error) {
+	skills := []string{}
+	for _, v := range splitList(value) {
+		if preset, ok := SKILL_PRESETS[v]; ok {
+			skills = appendUnique(skills, preset...)
+			continue
+		}
+		if v == "overall" || !contains(hiscores.SKILLS, v) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSkill, v)
+		}
+		skills = appendUnique(skills, v)
+	}
+
+	return skills, nil
+}
+
+// ParseActivities parses a comma separated list of hiscores activities and bosses, e.g. "zulrah,clue_scrolls_all".
+func ParseActivities(value string) ([]string, error) {
+	activities := []string{}
+	for _, v := range splitList(value) {
+		if !contains(hiscores.ACTIVITIES, v) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownActivity, v)
+		}
+		activities = appendUnique(activities, v)
+	}
+
+	return activities, nil
+}
+
+// Reasons a tracked skill or activity could not be read from a snapshot.
+const (
+	SkillMissing  = "missing from the hiscores"
+	SkillUnranked = "unranked"
+)
+
+// snapshot returns the xp of a player in the given skills and their score in the given activities, and the reason
+// for every one of them that could not be read. Unranked activities count as a score of 0, as players are only ranked
+// in them once they reach a minimum score.
+func snapshot(player *hiscores.Player, skills []string, activities []string) (XpTable, ScoreTable, map[string]string) {
+	xp := XpTable{}
+	scores := ScoreTable{}
+	failed := make(map[string]string)
+	for _, skill := range skills {
+		s, ok := player.GetSkill(skill)
+		switch {
+		case !ok:
+			failed[skill] = SkillMissing
+		case s.Xp < 0:
+			failed[skill] = SkillUnranked
+		default:
+			xp[skill] = s.Xp
+		}
+	}
+	for _, activity := range activities {
+		a, ok := player.GetActivity(activity)
+		switch {
+		case !ok:
+			failed[activity] = SkillMissing
+		case a.Score < 0:
+			scores[activity] = 0
+		default:
+			scores[activity] = a.Score
+		}
+	}
+
+	return xp, scores, failed
+}
@@ -0,0 +1,79 @@
+package xptracker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores/hiscorestest"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func TestParseSkills(t *testing.T) {
+	t.Parallel()
+
+	skills, err := ParseSkills("Attack, strength,slayer,attack")
+	if err != nil || !reflect.DeepEqual(skills, []string{"attack", "strength", "slayer"}) {
+		t.Errorf("Unexpected skills %v (%v)", skills, err)
+	}
+
+	skills, err = ParseSkills("combat,prayer,magic")
+	if err != nil || !reflect.DeepEqual(skills, append(append([]string{}, COMBAT_SKILLS...), "prayer")) {
+		t.Errorf("Unexpected skills %v (%v)", skills, err)
+	}
+
+	if skills, _ := ParseSkills("all"); len(skills) != len(hiscores.SKILLS)-1 {
+		t.Errorf("Expected all to name every skill but overall, got %v", skills)
+	}
+
+	for _, value := range []string{"overall", "sailing", "zulrah"} {
+		if _, err := ParseSkills(value); !errors.Is(err, ErrUnknownSkill) {
+			t.Errorf("Expected %q to be an unknown skill, got %v", value, err)
+		}
+	}
+}
+
+func TestParseActivities(t *testing.T) {
+	t.Parallel()
+
+	activities, err := ParseActivities("Zulrah,clue_scrolls_all")
+	if err != nil || !reflect.DeepEqual(activities, []string{"zulrah", "clue_scrolls_all"}) {
+		t.Errorf("Unexpected activities %v (%v)", activities, err)
+	}
+	if _, err := ParseActivities("attack"); !errors.Is(err, ErrUnknownActivity) {
+		t.Errorf("Expected attack to be an unknown activity, got %v", err)
+	}
+}
+
+func TestEventTracksChosenSkillsAndActivities(t *testing.T) {
+	t.Parallel()
+
+	server := hiscorestest.NewServer()
+	defer server.Close()
+	server.SetPlayer("Corgi", map[string]int64{"slayer": 1000, "attack": 5000}, map[string]int64{"zulrah": 10})
+
+	opts := hiscores.PoolOptions{Workers: 1}
+
+	members := []memberlistentity.Member{{Name: "Corgi", Accounts: memberlistentity.RuneScapeAccounts{{Tag: "lpc", RuneScapeName: "Corgi"}}}}
+	event := newXpTrackerEvent("Bossing", members, "lpc", []string{"slayer"}, []string{"zulrah", "vorkath"}, server.Lookup(), opts, nil)
+
+	server.SetPlayer("Corgi", map[string]int64{"slayer": 1500, "attack": 9000}, map[string]int64{"zulrah": 25, "vorkath": 50})
+	event.end(server.Lookup(), opts, nil)
+
+	corgi := event.GetParticipant("Corgi")
+	if !reflect.DeepEqual(corgi.XpGainedTable, XpTable{"slayer": 500}) {
+		t.Errorf("Expected only slayer xp to be tracked, got %v", corgi.XpGainedTable)
+	}
+	if !reflect.DeepEqual(corgi.ScoreGainedTable, ScoreTable{"zulrah": 15, "vorkath": 50}) {
+		t.Errorf("Expected zulrah and vorkath kills to be tracked, got %v", corgi.ScoreGainedTable)
+	}
+	if _, ok := corgi.SkillErrors["vorkath"]; ok {
+		t.Errorf("Expected vorkath being unranked at the start to count as no kills, got %v", corgi.SkillErrors)
+	}
+
+	legacy := &XpTrackerEvent{}
+	if !reflect.DeepEqual(legacy.GetSkills(), COMBAT_SKILLS) {
+		t.Errorf("Expected events without skills to track combat skills, got %v", legacy.GetSkills())
+	}
+}
@@ -1,29 +1,50 @@
 package xptracker
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
 	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
 	"github.com/joeydotdev/corgi-discord-bot/internal/storage"
-	hiscores "github.com/joeydotdev/osrs-hiscores"
 )
 
 // XpTable is a map of skills to their xp.
 type XpTable = map[string]int64
 
+// ScoreTable is a map of activities and bosses to their score or kill count.
+type ScoreTable = map[string]int64
+
+var ErrEventNotActive error = errors.New("xp tracker event is not active")
+var ErrEventAbandoned error = errors.New("xp tracker event was abandoned")
+
 type Participant struct {
 	// Name is the name of the participant.
 	Name string `json:"name"`
+	// Account is the tag of the participant's tracked account.
+	Account string `json:"account"`
 	// RuneScapeName is the runescape name of the participant.
 	RuneScapeName string `json:"runescape_name"`
 	// InitialXpTable is the initial xp state of the participant.
 	InitialXpTable XpTable `json:"xp_table"`
 	// XpGainedTable is the xp gained by the participant after event has been concluded.
 	XpGainedTable XpTable `json:"xp_gained_table"`
+	// InitialScoreTable is the initial score of the participant in the tracked activities.
+	InitialScoreTable ScoreTable `json:"score_table,omitempty"`
+	// ScoreGainedTable is the score gained by the participant in the tracked activities after the event has been concluded.
+	ScoreGainedTable ScoreTable `json:"score_gained_table,omitempty"`
+	// Error is why the participant could not be looked up on the hiscores at the start of the event, or at the end of it
+	// for tracked participants. Participants failing at the start are not tracked, and those failing at the end have no
+	// gains recorded, until they are retried successfully.
+	Error string `json:"error,omitempty"`
+	// SkillErrors maps tracked skills and activities that could not be read from either snapshot to the reason why. No
+	// gain is recorded for these.
+	SkillErrors map[string]string `json:"skill_errors,omitempty"`
 }
 
 type XpTrackerEvent struct {
@@ -31,6 +52,8 @@ type XpTrackerEvent struct {
 	Uuid string `json:"uuid"`
 	// Name is the name of the event.
 	Name string `json:"name"`
+	// ShortName is the name the event can be addressed by in commands, e.g. combat-week.
+	ShortName string `json:"short_name,omitempty"`
 	// IsActive is whether or not the given event is active.
 	IsActive bool `json:"is_active"`
 	// InitialMembersXpList is a list of members and their initial combat xps.
@@ -39,58 +62,193 @@ type XpTrackerEvent struct {
 	StartDate string `json:"start_date"`
 	// EndDate is the end date of the event.
 	EndDate string `json:"end_date"`
+	// Skills are the skills whose xp is tracked. Events stored before skills could be chosen tracked COMBAT_SKILLS.
+	Skills []string `json:"skills,omitempty"`
+	// Activities are the activities and bosses whose score is tracked.
+	Activities []string `json:"activities,omitempty"`
+	// SnapshotIntervalMinutes is how many minutes apart interval snapshots are taken while the event is active. Zero
+	// means no interval snapshots are taken.
+	SnapshotIntervalMinutes int `json:"snapshot_interval_minutes,omitempty"`
+	// Abandoned is whether the event was ended without a final snapshot, so no gains were recorded.
+	Abandoned bool `json:"abandoned,omitempty"`
+
+	// mu guards the event while it is ended, retried or abandoned. Other goroutines read it through Copy.
+	mu sync.RWMutex
 }
 
-// NewXpTrackerEvent creates a new xp tracker event.
-func NewXpTrackerEvent(name string, members []memberlistentity.Member) *XpTrackerEvent {
-	hiscores := hiscores.NewHiscores()
-	participants := []Participant{}
+// Progress is called as hiscores lookups finish with the number of finished and total lookups.
+type Progress = func(done int, total int)
+
+// IsTracked returns whether the participant's initial snapshot succeeded.
+func (p *Participant) IsTracked() bool {
+	return len(p.Error) == 0 || p.InitialXpTable != nil
+}
+
+// GetSkills returns the skills tracked by the event.
+func (x *XpTrackerEvent) GetSkills() []string {
+	if len(x.Skills) == 0 && len(x.Activities) == 0 {
+		return COMBAT_SKILLS
+	}
+
+	return x.Skills
+}
+
+// snapshotInitial records the result of looking the participant up at the start of the event.
+func (p *Participant) snapshotInitial(result hiscores.LookupResult, skills []string, activities []string) {
+	if result.Err != nil {
+		p.Error = result.Err.Error()
+		p.InitialXpTable = nil
+		p.InitialScoreTable = nil
+		p.SkillErrors = nil
+		return
+	}
+
+	p.Error = ""
+	p.InitialXpTable, p.InitialScoreTable, p.SkillErrors = snapshot(result.Player, skills, activities)
+	if len(p.InitialScoreTable) == 0 {
+		p.InitialScoreTable = nil
+	}
+	if len(p.SkillErrors) == 0 {
+		p.SkillErrors = nil
+	}
+}
+
+// snapshotFinal records the result of looking the participant up at the end of the event.
+func (p *Participant) snapshotFinal(result hiscores.LookupResult, skills []string, activities []string) {
+	if result.Err != nil {
+		p.Error = result.Err.Error()
+		return
+	}
+
+	p.Error = ""
+	p.recordGain(result.Player, skills, activities)
+}
+
+// NewXpTrackerEvent creates a new xp tracker event tracking the given skills and activities of the members' accounts
+// with the given tag. If neither skills nor activities are given, COMBAT_SKILLS are tracked. Members without an account with that tag do not take part. Players are looked up concurrently through the hiscores
+// pool, and progress, if set, is reported as lookups finish. Members that could not be looked up are kept as failed
+// participants so they can be retried. Interval snapshots are taken every snapshotIntervalMinutes, or not at all if it
+// is zero.
+func NewXpTrackerEvent(name string, members []memberlistentity.Member, account string, skills []string, activities []string, snapshotIntervalMinutes int, lookup hiscores.Lookup, progress Progress) *XpTrackerEvent {
+	event := newXpTrackerEvent(name, members, account, skills, activities, lookup, hiscores.DefaultPoolOptions, progress)
+	event.SnapshotIntervalMinutes = snapshotIntervalMinutes
+	event.sync()
+	return event
+}
+
+// newXpTrackerEvent creates a new xp tracker event without storing it, spreading the lookups over the hiscores as opts
+// allow.
+func newXpTrackerEvent(name string, members []memberlistentity.Member, account string, skills []string, activities []string, lookup hiscores.Lookup, opts hiscores.PoolOptions, progress Progress) *XpTrackerEvent {
+	if len(skills) == 0 && len(activities) == 0 {
+		skills = COMBAT_SKILLS
+	}
 
+	rsns := []string{}
 	for _, v := range members {
-		attackXp, err := hiscores.GetPlayerSkillXp(v.Accounts.LPC, "attack")
-		strengthXp, err := hiscores.GetPlayerSkillXp(v.Accounts.LPC, "strength")
-		defenceXp, err := hiscores.GetPlayerSkillXp(v.Accounts.LPC, "defence")
-		rangedXp, err := hiscores.GetPlayerSkillXp(v.Accounts.LPC, "ranged")
-		magicXp, err := hiscores.GetPlayerSkillXp(v.Accounts.LPC, "magic")
-		hitpointsXp, err := hiscores.GetPlayerSkillXp(v.Accounts.LPC, "hitpoints")
-		if err != nil {
-			log.Printf(err.Error())
+		if rsn := v.GetAccount(account); len(rsn) > 0 {
+			rsns = append(rsns, rsn)
+		}
+	}
+	results := hiscores.LookupPlayers(rsns, lookup, opts, progress)
+
+	participants := []Participant{}
+	for _, v := range members {
+		rsn := v.GetAccount(account)
+		if len(rsn) == 0 {
 			continue
 		}
 
-		participants = append(participants, Participant{
+		participant := Participant{
 			Name:          v.Name,
-			RuneScapeName: v.Accounts.LPC,
-			InitialXpTable: XpTable{
-				"attack":    attackXp,
-				"strength":  strengthXp,
-				"defence":   defenceXp,
-				"ranged":    rangedXp,
-				"magic":     magicXp,
-				"hitpoints": hitpointsXp,
-			},
-		})
-	}
-
-	event := &XpTrackerEvent{
+			Account:       account,
+			RuneScapeName: rsn,
+		}
+		participant.snapshotInitial(results[rsn], skills, activities)
+		if !participant.IsTracked() {
+			log.Printf("Failed to snapshot %s (%s): %s", v.Name, rsn, participant.Error)
+		}
+		participants = append(participants, participant)
+	}
+
+	return &XpTrackerEvent{
 		Uuid:         uuid.New().String(),
 		Name:         name,
+		ShortName:    ShortNameOf(name),
 		IsActive:     true,
 		Participants: participants,
 		StartDate:    time.Now().Format(time.RFC3339),
 		EndDate:      "",
+		Skills:       skills,
+		Activities:   activities,
 	}
-
-	event.sync()
-	return event
 }
 
-// sync syncs the xp tracker event metadata to data store.
+// sync syncs the xp tracker event metadata to data store. The caller must not hold the lock.
 func (x *XpTrackerEvent) sync() error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
 	err := storage.UploadJSON(fmt.Sprintf("xptracker/%s.json", x.Uuid), x)
 	return err
 }
 
+// copyTable returns a copy of a table, keeping nil tables nil.
+func copyTable(table map[string]int64) map[string]int64 {
+	if table == nil {
+		return nil
+	}
+
+	copied := make(map[string]int64, len(table))
+	for k, v := range table {
+		copied[k] = v
+	}
+
+	return copied
+}
+
+// clone returns a deep copy of the participant.
+func (p Participant) clone() Participant {
+	p.InitialXpTable = copyTable(p.InitialXpTable)
+	p.XpGainedTable = copyTable(p.XpGainedTable)
+	p.InitialScoreTable = copyTable(p.InitialScoreTable)
+	p.ScoreGainedTable = copyTable(p.ScoreGainedTable)
+	if p.SkillErrors != nil {
+		skillErrors := make(map[string]string, len(p.SkillErrors))
+		for k, v := range p.SkillErrors {
+			skillErrors[k] = v
+		}
+		p.SkillErrors = skillErrors
+	}
+
+	return p
+}
+
+// Copy returns a deep copy of the event, consistent even while the event is being ended or retried. Goroutines other
+// than the one changing the event, such as live leaderboards, read the copy instead.
+func (x *XpTrackerEvent) Copy() *XpTrackerEvent {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	participants := make([]Participant, len(x.Participants))
+	for i, v := range x.Participants {
+		participants[i] = v.clone()
+	}
+
+	return &XpTrackerEvent{
+		Uuid:                    x.Uuid,
+		Name:                    x.Name,
+		ShortName:               x.ShortName,
+		IsActive:                x.IsActive,
+		Participants:            participants,
+		StartDate:               x.StartDate,
+		EndDate:                 x.EndDate,
+		Skills:                  append([]string(nil), x.Skills...),
+		Activities:              append([]string(nil), x.Activities...),
+		SnapshotIntervalMinutes: x.SnapshotIntervalMinutes,
+		Abandoned:               x.Abandoned,
+	}
+}
+
 // GetParticipantCount returns the number of participants in the event.
 func (x *XpTrackerEvent) GetParticipantCount() int {
 	return len(x.Participants)
@@ -113,56 +271,156 @@ func (x *XpTrackerEvent) GetEventDuration() string {
 	return end.Sub(start).String()
 }
 
-// GetParticipantXpGain returns the xp gained by a participant.
-func (x *XpTrackerEvent) GetParticipantXpGain(participantName string) (XpTable, error) {
-	var selectedParticipant Participant
+// gains returns the xp and score a participant gained between the start of the event and a snapshot of the player,
+// and the reasons for the skills and activities that could not be read from the snapshot.
+func (p *Participant) gains(player *hiscores.Player, skills []string, activities []string) (XpTable, ScoreTable, map[string]string) {
+	finalXp, finalScores, failed := snapshot(player, skills, activities)
+	gained := XpTable{}
+	for skill, xp := range finalXp {
+		if initial, ok := p.InitialXpTable[skill]; ok {
+			gained[skill] = xp - initial
+		}
+	}
+	scoreGained := ScoreTable{}
+	for activity, score := range finalScores {
+		if initial, ok := p.InitialScoreTable[activity]; ok {
+			scoreGained[activity] = score - initial
+		}
+	}
+
+	return gained, scoreGained, failed
+}
+
+// recordGain records the xp and score a participant gained since the start of the event. Skills and activities
+// missing from either snapshot are recorded in SkillErrors rather than the gains.
+func (p *Participant) recordGain(player *hiscores.Player, skills []string, activities []string) {
+	gained, scoreGained, failed := p.gains(player, skills, activities)
+	for skill, reason := range failed {
+		if _, ok := p.SkillErrors[skill]; ok {
+			continue
+		}
+		if p.SkillErrors == nil {
+			p.SkillErrors = make(map[string]string)
+		}
+		p.SkillErrors[skill] = reason
+	}
+
+	p.XpGainedTable = gained
+	if len(scoreGained) > 0 {
+		p.ScoreGainedTable = scoreGained
+	}
+}
+
+// IsFailed returns whether a participant's snapshot failed and can be retried: the initial snapshot while the event is
+// active, or the final snapshot once it has ended.
+func (x *XpTrackerEvent) IsFailed(p Participant) bool {
+	if x.IsActive || x.Abandoned {
+		return !p.IsTracked()
+	}
+
+	return p.IsTracked() && len(p.Error) > 0
+}
+
+// GetFailedParticipants returns the participants whose snapshot failed, see IsFailed.
+func (x *XpTrackerEvent) GetFailedParticipants() []Participant {
+	failed := []Participant{}
 	for _, v := range x.Participants {
-		if v.Name == participantName {
-			selectedParticipant = v
-			break
+		if x.IsFailed(v) {
+			failed = append(failed, v)
 		}
 	}
 
-	if selectedParticipant.Name == "" {
-		return nil, nil
+	return failed
+}
+
+// lookupParticipants looks the participants matching a filter up concurrently and returns the results by RSN.
+func (x *XpTrackerEvent) lookupParticipants(filter func(Participant) bool, lookup hiscores.Lookup, opts hiscores.PoolOptions, progress Progress) map[string]hiscores.LookupResult {
+	rsns := []string{}
+	for _, v := range x.Participants {
+		if filter(v) {
+			rsns = append(rsns, v.RuneScapeName)
+		}
 	}
 
-	hiscores := hiscores.NewHiscores()
-	attackXp, err := hiscores.GetPlayerSkillXp(selectedParticipant.RuneScapeName, "attack")
-	strengthXp, err := hiscores.GetPlayerSkillXp(selectedParticipant.RuneScapeName, "strength")
-	defenceXp, err := hiscores.GetPlayerSkillXp(selectedParticipant.RuneScapeName, "defence")
-	rangedXp, err := hiscores.GetPlayerSkillXp(selectedParticipant.RuneScapeName, "ranged")
-	magicXp, err := hiscores.GetPlayerSkillXp(selectedParticipant.RuneScapeName, "magic")
-	hitpointsXp, err := hiscores.GetPlayerSkillXp(selectedParticipant.RuneScapeName, "hitpoints")
+	return hiscores.LookupPlayers(rsns, lookup, opts, progress)
+}
+
+// Retry re-snapshots the participants whose snapshot failed, see IsFailed, and returns those that still could not be
+// looked up. Abandoned events cannot be retried.
+func (x *XpTrackerEvent) Retry(lookup hiscores.Lookup, progress Progress) ([]Participant, error) {
+	x.mu.Lock()
+	err := x.retry(lookup, hiscores.DefaultPoolOptions, progress)
+	failed := x.GetFailedParticipants()
+	x.mu.Unlock()
 	if err != nil {
 		return nil, err
 	}
 
-	return XpTable{
-		"attack":    attackXp - selectedParticipant.InitialXpTable["attack"],
-		"strength":  strengthXp - selectedParticipant.InitialXpTable["strength"],
-		"defence":   defenceXp - selectedParticipant.InitialXpTable["defence"],
-		"ranged":    rangedXp - selectedParticipant.InitialXpTable["ranged"],
-		"magic":     magicXp - selectedParticipant.InitialXpTable["magic"],
-		"hitpoints": hitpointsXp - selectedParticipant.InitialXpTable["hitpoints"],
-	}, nil
+	return failed, x.sync()
+}
+
+// retry re-snapshots the participants whose snapshot failed without storing the event.
+func (x *XpTrackerEvent) retry(lookup hiscores.Lookup, opts hiscores.PoolOptions, progress Progress) error {
+	if x.Abandoned {
+		return ErrEventAbandoned
+	}
+
+	results := x.lookupParticipants(x.IsFailed, lookup, opts, progress)
+	for i, v := range x.Participants {
+		if !x.IsFailed(v) {
+			continue
+		}
+
+		if x.IsActive {
+			x.Participants[i].snapshotInitial(results[v.RuneScapeName], x.GetSkills(), x.Activities)
+		} else {
+			x.Participants[i].snapshotFinal(results[v.RuneScapeName], x.GetSkills(), x.Activities)
+		}
+	}
+
+	return nil
+}
+
+// EndEvent ends the event, looking every tracked participant up concurrently to work out their gains. Participants that
+// could not be looked up keep the reason in their Error and can be retried. progress, if set, is reported as lookups
+// finish.
+func (x *XpTrackerEvent) EndEvent(lookup hiscores.Lookup, progress Progress) {
+	x.mu.Lock()
+	x.end(lookup, hiscores.DefaultPoolOptions, progress)
+	x.mu.Unlock()
+
+	x.sync()
 }
 
-// EndEvent ends the event.
-func (x *XpTrackerEvent) EndEvent() {
+// end ends the event without storing it.
+func (x *XpTrackerEvent) end(lookup hiscores.Lookup, opts hiscores.PoolOptions, progress Progress) {
 	x.IsActive = false
 	x.EndDate = time.Now().Format(time.RFC3339)
 
+	isTracked := func(p Participant) bool { return p.IsTracked() }
+	results := x.lookupParticipants(isTracked, lookup, opts, progress)
 	for i, v := range x.Participants {
-		xpGained, err := x.GetParticipantXpGain(v.Name)
-		if err != nil {
-			log.Printf(err.Error())
+		if !isTracked(v) {
 			continue
 		}
 
-		x.Participants[i].XpGainedTable = xpGained
+		result := results[v.RuneScapeName]
+		if result.Err != nil {
+			log.Printf("Failed to snapshot %s (%s): %v", v.Name, v.RuneScapeName, result.Err)
+		}
+		x.Participants[i].snapshotFinal(result, x.GetSkills(), x.Activities)
 	}
-	x.sync()
+}
+
+// Abandon ends the event without a final snapshot, e.g. when it is replaced by a new event.
+func (x *XpTrackerEvent) Abandon() error {
+	x.mu.Lock()
+	x.IsActive = false
+	x.Abandoned = true
+	x.EndDate = time.Now().Format(time.RFC3339)
+	x.mu.Unlock()
+
+	return x.sync()
 }
 
 // GetXpTrackerEventByUUID returns an xp tracker event by uuid.
@@ -178,12 +436,64 @@ func GetXpTrackerEventByUUID(uuid string) (*XpTrackerEvent, error) {
 
 // GetXpTrackerEventUUIDs returns a list of xp tracker event uuids.
 func GetXpTrackerEventUUIDs() ([]string, error) {
-	files, err := storage.ListObjects("xptracker")
+	files, err := storage.ListObjects("xptracker/")
 	if err != nil {
 		return nil, err
 	}
-	for i, v := range files {
-		files[i] = strings.Replace(v, ".json", "", -1)
+	uuids := []string{}
+	for _, v := range files {
+		uuids = append(uuids, strings.TrimSuffix(strings.TrimPrefix(v, "xptracker/"), ".json"))
 	}
-	return files, nil
+	return uuids, nil
+}
+
+// GetXpTrackerEvents returns every stored xp tracker event.
+func GetXpTrackerEvents() ([]*XpTrackerEvent, error) {
+	uuids, err := GetXpTrackerEventUUIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*XpTrackerEvent{}
+	for _, v := range uuids {
+		event, err := GetXpTrackerEventByUUID(v)
+		if err != nil {
+			log.Printf("Failed to download xp tracker event %s: %v", v, err)
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// GetActiveXpTrackerEvents returns the stored events that are still active, most recently started first.
+func GetActiveXpTrackerEvents() ([]*XpTrackerEvent, error) {
+	events, err := GetXpTrackerEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	return filterActive(events), nil
+}
+
+// GetParticipant returns the participant with the given name, or nil if they did not take part in the event.
+func (x *XpTrackerEvent) GetParticipant(participantName string) *Participant {
+	for _, v := range x.Participants {
+		if v.Name == participantName {
+			return &v
+		}
+	}
+
+	return nil
+}
+
+// GetTotalXp returns the sum of the xp in a table.
+func GetTotalXp(table XpTable) int64 {
+	var total int64
+	for _, xp := range table {
+		total += xp
+	}
+
+	return total
 }
@@ -0,0 +1,169 @@
+package xptracker
+
+import (
+	"testing"
+
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores"
+	"github.com/joeydotdev/corgi-discord-bot/internal/hiscores/hiscorestest"
+	memberlistentity "github.com/joeydotdev/corgi-discord-bot/internal/memberlist"
+)
+
+func playerWithXp(xp map[string]int64) *hiscores.Player {
+	player := &hiscores.Player{Skills: make(map[string]hiscores.Skill)}
+	for skill, v := range xp {
+		player.Skills[skill] = hiscores.Skill{Rank: 1, Level: 1, Xp: v}
+	}
+
+	return player
+}
+
+func TestSnapshotRecordsSkillErrors(t *testing.T) {
+	t.Parallel()
+
+	player := playerWithXp(map[string]int64{"attack": 100, "strength": 200, "defence": 300, "ranged": -1, "hitpoints": 1154})
+
+	participant := Participant{Name: "Joey", RuneScapeName: "joey"}
+	participant.snapshotInitial(hiscores.LookupResult{Player: player}, COMBAT_SKILLS, nil)
+	if !participant.IsTracked() {
+		t.Fatalf("Expected participant to be tracked, got error %q", participant.Error)
+	}
+	if participant.SkillErrors["ranged"] != SkillUnranked || participant.SkillErrors["magic"] != SkillMissing {
+		t.Errorf("Unexpected skill errors %v", participant.SkillErrors)
+	}
+	if _, ok := participant.InitialXpTable["ranged"]; ok {
+		t.Errorf("Expected unranked skill to be left out of the snapshot")
+	}
+
+	participant.recordGain(playerWithXp(map[string]int64{"attack": 150, "strength": 200, "defence": 300, "ranged": 5000, "magic": 20, "hitpoints": -1}), COMBAT_SKILLS, nil)
+	if participant.XpGainedTable["attack"] != 50 || participant.XpGainedTable["strength"] != 0 {
+		t.Errorf("Unexpected gains %v", participant.XpGainedTable)
+	}
+	for _, skill := range []string{"ranged", "magic", "hitpoints"} {
+		if _, ok := participant.XpGainedTable[skill]; ok {
+			t.Errorf("Expected no gain to be recorded in %s", skill)
+		}
+	}
+	if participant.SkillErrors["hitpoints"] != SkillUnranked {
+		t.Errorf("Expected final snapshot failure to be recorded, got %v", participant.SkillErrors)
+	}
+}
+
+func TestSnapshotRecordsLookupFailure(t *testing.T) {
+	t.Parallel()
+
+	participant := Participant{Name: "Joey", RuneScapeName: "joey"}
+	participant.snapshotInitial(hiscores.LookupResult{Err: hiscores.ErrPlayerNotFound}, COMBAT_SKILLS, nil)
+	if participant.IsTracked() || participant.Error != hiscores.ErrPlayerNotFound.Error() {
+		t.Fatalf("Expected lookup failure to be recorded, got %q", participant.Error)
+	}
+
+	event := &XpTrackerEvent{IsActive: true, Participants: []Participant{participant, {Name: "Corgi"}}}
+	if failed := event.GetFailedParticipants(); len(failed) != 1 || failed[0].Name != "Joey" {
+		t.Errorf("Unexpected failed participants %v", failed)
+	}
+
+	participant.snapshotInitial(hiscores.LookupResult{Player: playerWithXp(map[string]int64{"attack": 1})}, COMBAT_SKILLS, nil)
+	if !participant.IsTracked() {
+		t.Errorf("Expected successful retry to clear the error")
+	}
+}
+
+func TestSnapshotRecordsFinalLookupFailure(t *testing.T) {
+	t.Parallel()
+
+	participant := Participant{Name: "Joey", RuneScapeName: "joey"}
+	participant.snapshotInitial(hiscores.LookupResult{Player: playerWithXp(map[string]int64{"attack": 100})}, COMBAT_SKILLS, nil)
+	participant.snapshotFinal(hiscores.LookupResult{Err: hiscores.ErrPlayerNotFound}, COMBAT_SKILLS, nil)
+	if !participant.IsTracked() || participant.Error != hiscores.ErrPlayerNotFound.Error() || participant.XpGainedTable != nil {
+		t.Fatalf("Expected final lookup failure to be recorded, got %+v", participant)
+	}
+
+	event := &XpTrackerEvent{Participants: []Participant{participant, {Name: "Corgi", Error: "not found"}}}
+	if failed := event.GetFailedParticipants(); len(failed) != 1 || failed[0].Name != "Joey" {
+		t.Errorf("Expected only Joey to need a final snapshot, got %v", failed)
+	}
+
+	participant.snapshotFinal(hiscores.LookupResult{Player: playerWithXp(map[string]int64{"attack": 150})}, COMBAT_SKILLS, nil)
+	if len(participant.Error) > 0 || participant.XpGainedTable["attack"] != 50 {
+		t.Errorf("Expected successful retry to record the gains, got %+v", participant)
+	}
+}
+
+func TestEventAgainstStubHiscores(t *testing.T) {
+	t.Parallel()
+
+	server := hiscorestest.NewServer()
+	defer server.Close()
+	server.SetXp("Corgi", map[string]int64{"attack": 1000, "strength": 1000, "defence": 1000, "ranged": 1000, "magic": 1000, "hitpoints": 1154})
+	server.SetUnavailable("Flaky")
+
+	members := []memberlistentity.Member{
+		{Name: "Corgi", Accounts: memberlistentity.RuneScapeAccounts{{Tag: "lpc", RuneScapeName: "Corgi"}}},
+		{Name: "Flaky", Accounts: memberlistentity.RuneScapeAccounts{{Tag: "lpc", RuneScapeName: "Flaky"}}},
+		{Name: "Skiller"},
+	}
+	lookup := hiscores.LookupWith(server.Client(), hiscores.AccountTypeNormal)
+	opts := hiscores.PoolOptions{Workers: 2}
+
+	event := newXpTrackerEvent("Test", members, "lpc", nil, nil, lookup, opts, nil)
+	if len(event.Participants) != 2 {
+		t.Fatalf("Expected members with an lpc account to take part, got %v", event.Participants)
+	}
+	if failed := event.GetFailedParticipants(); len(failed) != 1 || failed[0].Name != "Flaky" {
+		t.Fatalf("Expected Flaky to fail, got %v", failed)
+	}
+
+	server.SetXp("Flaky", map[string]int64{"attack": 50})
+	if err := event.retry(lookup, opts, nil); err != nil || len(event.GetFailedParticipants()) != 0 {
+		t.Fatalf("Expected retry to track Flaky, got %v", err)
+	}
+
+	server.SetXp("Corgi", map[string]int64{"attack": 1500, "strength": 1000, "defence": 1000, "ranged": 1000, "magic": 1000, "hitpoints": 1320})
+	server.SetUnavailable("Flaky")
+	event.end(lookup, opts, nil)
+
+	if corgi := event.GetParticipant("Corgi"); GetTotalXp(corgi.XpGainedTable) != 666 {
+		t.Errorf("Expected Corgi to gain 666 xp, got %v", corgi.XpGainedTable)
+	}
+	if event.IsActive {
+		t.Errorf("Expected event to end")
+	}
+	flaky := event.GetParticipant("Flaky")
+	if !flaky.IsTracked() || len(flaky.Error) == 0 || flaky.XpGainedTable != nil {
+		t.Fatalf("Expected Flaky's final lookup failure to be recorded, got %+v", flaky)
+	}
+	if failed := event.GetFailedParticipants(); len(failed) != 1 || failed[0].Name != "Flaky" {
+		t.Fatalf("Expected Flaky to need a final snapshot, got %v", failed)
+	}
+	if standings := event.getStandings(lookup, opts, nil); len(standings) != 2 || standings[1].Err == nil {
+		t.Errorf("Expected Flaky's standing to fail, got %+v", standings)
+	}
+
+	server.SetXp("Flaky", map[string]int64{"attack": 150})
+	if err := event.retry(lookup, opts, nil); err != nil || len(event.GetFailedParticipants()) != 0 {
+		t.Fatalf("Expected retry to record Flaky's gains, got %v", err)
+	}
+	if flaky := event.GetParticipant("Flaky"); flaky.XpGainedTable["attack"] != 100 || flaky.SkillErrors["strength"] != SkillUnranked {
+		t.Errorf("Unexpected Flaky result %+v", flaky)
+	}
+
+	abandoned := &XpTrackerEvent{Abandoned: true}
+	if err := abandoned.retry(lookup, opts, nil); err != ErrEventAbandoned {
+		t.Errorf("Expected abandoned event not to be retried, got %v", err)
+	}
+}
+
+func TestFilterActive(t *testing.T) {
+	t.Parallel()
+
+	events := []*XpTrackerEvent{
+		{Uuid: "old", IsActive: true, StartDate: "2023-01-01T10:00:00Z"},
+		{Uuid: "ended", IsActive: false, StartDate: "2023-03-01T10:00:00Z"},
+		{Uuid: "new", IsActive: true, StartDate: "2023-02-01T12:00:00+02:00"},
+	}
+
+	active := filterActive(events)
+	if len(active) != 2 || active[0].Uuid != "new" || active[1].Uuid != "old" {
+		t.Errorf("Expected active events newest first, got %v", active)
+	}
+}
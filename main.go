@@ -8,6 +8,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	discordHandlers "github.com/joeydotdev/corgi-discord-bot/internal/handlers"
+	"github.com/joeydotdev/corgi-discord-bot/internal/plugins"
 )
 
 var session *discordgo.Session
@@ -26,6 +27,11 @@ func init() {
 	session.AddHandler(handlers.PresenceUpdate)
 	session.AddHandler(handlers.MessageCreate)
 	session.AddHandler(handlers.Ready)
+	session.AddHandler(handlers.InteractionCreate)
+	session.AddHandler(handlers.GuildMemberAdd)
+	session.AddHandler(handlers.GuildMemberUpdate)
+	session.AddHandler(handlers.GuildMemberRemove)
+	session.AddHandler(handlers.VoiceStateUpdate)
 
 	err = session.Open()
 	if err != nil {
@@ -42,5 +48,8 @@ func main() {
 	<-sig
 
 	// clean up
+	if err := plugins.FlushActivity(); err != nil {
+		fmt.Println("Failed to save activity: ", err)
+	}
 	session.Close()
 }